@@ -10,33 +10,79 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mkimuram/k8sviz/pkg/graph"
 	"github.com/mkimuram/k8sviz/pkg/resources"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/clientcmd"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
 const (
-	defaultNamespace   = "default"
-	defaultOutFile     = "k8sviz.out"
-	defaultOutType     = "dot"
-	descNamespaceOpt   = "namespace to visualize"
-	descOutFileOpt     = "output filename"
-	descOutTypeOpt     = "type of output"
-	descShortOptSuffix = " (shorthand)"
+	defaultNamespace      = "default"
+	defaultOutFile        = "k8sviz.out"
+	defaultOutType        = "dot"
+	descNamespaceOpt      = "namespace to visualize"
+	descNamespacesOpt     = "comma separated list of namespaces to visualize (overrides -namespace)"
+	descAllNamespacesOpt  = "visualize all namespaces"
+	descOutFileOpt        = "output filename"
+	descOutTypeOpt        = "type of output"
+	descExtraKindsOpt     = "comma separated list of extra kinds to visualize, like CRDs (ex. rollouts.argoproj.io)"
+	descSelectorOpt       = "label selector to filter resources (resources related to the selected ones, like owners, are also visualized)"
+	descFieldSelectorOpt  = "field selector to filter resources (resources related to the selected ones, like owners, are also visualized)"
+	descTimeoutOpt        = "timeout to get resources from k8s cluster, like 30s (0 means no timeout)"
+	descSkipForbiddenOpt  = "skip kinds that are forbidden or unsupported, instead of failing (skipped kinds are noted in the diagram)"
+	descFromFileOpt       = "comma separated list of manifest files or directories to visualize instead of k8s cluster (\"-\" for stdin)"
+	descSnapshotOpt       = "snapshot file to visualize (only for render command)"
+	descWatchOpt          = "watch resources and regenerate the output file on changes"
+	descTrafficOpt        = "draw traffic between pods that network policies allow"
+	descEndpointSlicesOpt = "draw edges of services to pods from endpointslices, instead of selectors (endpoints not ready are drawn dashed)"
+	descGroupByNodeOpt    = "group pods by the k8s nodes that they are scheduled to"
+	descContainersOpt     = "draw containers, init containers and ephemeral containers in pods with their images, ports and restart counts"
+	descImagesOpt         = "draw images used by workloads grouped by registries (versions drifting in the same repository are outlined)"
+	descStorageOpt        = "draw persistentvolumes and storageclasses of pvcs, and capacity, access modes and phase of pvcs"
+	descPageSizeOpt       = "number of objects to get from k8s cluster in one request (more objects are got in the following requests)"
+	descShortOptSuffix    = " (shorthand)"
+	// Commands
+	cmdSnapshot = "snapshot"
+	cmdRender   = "render"
+	// watchDebounce is the time to wait for more changes before regenerating the output file
+	watchDebounce = 2 * time.Second
 )
 
 var (
-	clientset *kubernetes.Clientset
-	dir       string
+	clientset      kubernetes.Interface
+	dynamicClient  dynamic.Interface
+	metadataClient metadata.Interface
+	dir            string
+	command        string
+	kubeContext    string
 	// Flags
-	namespace string
-	outFile   string
-	outType   string
+	namespace      string
+	namespaces     string
+	allNamespaces  bool
+	outFile        string
+	outType        string
+	fromFile       string
+	extraKinds     string
+	selector       string
+	fieldSelector  string
+	timeout        time.Duration
+	skipForbidden  bool
+	snapshotFile   string
+	watch          bool
+	traffic        bool
+	endpointSlices bool
+	groupByNode    bool
+	storage        bool
+	containers     bool
+	images         bool
+	pageSize       int64
 )
 
 func init() {
@@ -51,31 +97,52 @@ func init() {
 	}
 	flag.StringVar(&namespace, "namespace", defaultNamespace, descNamespaceOpt)
 	flag.StringVar(&namespace, "n", defaultNamespace, descNamespaceOpt+descShortOptSuffix)
+	flag.StringVar(&namespaces, "namespaces", "", descNamespacesOpt)
+	flag.BoolVar(&allNamespaces, "all-namespaces", false, descAllNamespacesOpt)
+	flag.BoolVar(&allNamespaces, "A", false, descAllNamespacesOpt+descShortOptSuffix)
 	flag.StringVar(&outFile, "outfile", defaultOutFile, descOutFileOpt)
 	flag.StringVar(&outFile, "o", defaultOutFile, descOutFileOpt+descShortOptSuffix)
 	flag.StringVar(&outType, "type", defaultOutType, descOutTypeOpt)
 	flag.StringVar(&outType, "t", defaultOutType, descOutTypeOpt+descShortOptSuffix)
-	flag.Parse()
+	flag.StringVar(&fromFile, "from-file", "", descFromFileOpt)
+	flag.StringVar(&fromFile, "f", "", descFromFileOpt+descShortOptSuffix)
+	flag.StringVar(&extraKinds, "extra-kinds", "", descExtraKindsOpt)
+	flag.StringVar(&selector, "selector", "", descSelectorOpt)
+	flag.StringVar(&selector, "l", "", descSelectorOpt+descShortOptSuffix)
+	flag.StringVar(&fieldSelector, "field-selector", "", descFieldSelectorOpt)
+	flag.DurationVar(&timeout, "timeout", 0, descTimeoutOpt)
+	flag.BoolVar(&skipForbidden, "skip-forbidden", false, descSkipForbiddenOpt)
+	flag.StringVar(&snapshotFile, "snapshot", "", descSnapshotOpt)
+	flag.BoolVar(&watch, "watch", false, descWatchOpt)
+	flag.BoolVar(&traffic, "traffic", false, descTrafficOpt)
+	flag.BoolVar(&endpointSlices, "endpointslices", false, descEndpointSlicesOpt)
+	flag.BoolVar(&groupByNode, "group-by-node", false, descGroupByNodeOpt)
+	flag.BoolVar(&storage, "storage", false, descStorageOpt)
+	flag.BoolVar(&containers, "containers", false, descContainersOpt)
+	flag.BoolVar(&images, "images", false, descImagesOpt)
+	flag.Int64Var(&pageSize, "page-size", resources.DefaultPageSize, descPageSizeOpt)
+	flag.Usage = usage
 
-	// use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to build config from %q: %v\n", kubeconfig, err)
-		os.Exit(1)
-	}
-
-	// create the clientset
-	clientset, err = kubernetes.NewForConfig(config)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create client from %q: %v\n", kubeconfig, err)
-		os.Exit(1)
+	// Command is given before the flags, like `k8sviz snapshot -o snap.json`
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == cmdSnapshot || args[0] == cmdRender) {
+		command = args[0]
+		args = args[1:]
 	}
+	// Errors are handled by flag.ExitOnError
+	_ = flag.CommandLine.Parse(args)
 
-	// test connectivity for k8s cluster and the namespace
-	_, err = clientset.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get namespace %q: %v\n", namespace, err)
-		os.Exit(1)
+	if command == cmdRender {
+		if snapshotFile == "" {
+			fmt.Fprintf(os.Stderr, "-snapshot is required for %s command\n", cmdRender)
+			os.Exit(1)
+		}
+	} else {
+		clientset, dynamicClient, metadataClient, err = getClients(kubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get client: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	dir, err = getBinDir()
@@ -86,30 +153,237 @@ func init() {
 }
 
 func main() {
-	// Get all resources in the namespace
-	res, err := resources.NewResources(clientset, namespace)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get k8s resources: %v\n", err)
-		if strings.Contains(err.Error(), "the server could not find the requested resource") {
-			fmt.Fprintf(os.Stderr, "k8sviz 0.3.3 or later only support k8s 1.21 or later.\n")
-			fmt.Fprintf(os.Stderr, "If you are using older k8s cluster, try k8sviz 0.3.2 or earlier.\n")
+	if watch && command == "" {
+		watchResources()
+		return
+	}
+
+	var ress []*resources.Resources
+	if command == cmdRender {
+		snap, err := resources.ReadSnapshotFile(snapshotFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read snapshot %q: %v\n", snapshotFile, err)
+			os.Exit(1)
+		}
+		ress = snap.Resources
+	} else {
+		ress = collect()
+	}
+
+	if command == cmdSnapshot {
+		if err := resources.NewSnapshot(kubeContext, ress).WriteFile(outFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to output snapshot %q for namespace %q: %v\n", outFile, namespacesOf(ress), err)
+			os.Exit(1)
 		}
+		return
+	}
+
+	if err := draw(ress); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to draw k8s resources: %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	g := graph.NewGraph(res, dir)
+// draw outputs the graph for ress to the output file
+func draw(ress []*resources.Resources) error {
+	g := graph.NewGraphWithOptions(ress, dir, graph.Options{Traffic: traffic, EndpointSlices: endpointSlices, GroupByNode: groupByNode, Storage: storage, Containers: containers, Images: images})
 
 	if outType == "dot" {
 		if err := g.WriteDotFile(outFile); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to output %q file with format %q for namespace %q: %v\n", outFile, outType, namespace, err)
-			os.Exit(1)
+			return fmt.Errorf("failed to output %q file with format %q for namespace %q: %v", outFile, outType, namespacesOf(ress), err)
 		}
 	} else {
 		if err := g.PlotDotFile(outFile, outType); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to output %q file with format %q for namespace %q: %v\n", outFile, outType, namespace, err)
+			return fmt.Errorf("failed to output %q file with format %q for namespace %q: %v", outFile, outType, namespacesOf(ress), err)
+		}
+	}
+	return nil
+}
+
+// watchResources regenerates the output file each time resources change
+// It runs until the command is killed. -timeout applies to getting resources on start.
+func watchResources() {
+	ctx := context.Background()
+	nsCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		nsCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	namespaceList, err := getNamespaces(nsCtx, clientset)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get namespaces: %v\n", err)
+		os.Exit(1)
+	}
+
+	watcher := resources.NewWatcher(clientset, namespaceList, collectorOptions())
+	watcher.SyncTimeout = timeout
+	err = watcher.Run(ctx, watchDebounce, func(ress []*resources.Resources) {
+		if err := draw(ress); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to draw k8s resources: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Regenerated %q at %s\n", outFile, time.Now().Format(time.RFC3339))
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to watch k8s resources: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// namespacesOf returns the comma separated list of the namespaces of ress
+func namespacesOf(ress []*resources.Resources) string {
+	nss := []string{}
+	for _, res := range ress {
+		nss = append(nss, res.Namespace)
+	}
+	return strings.Join(nss, ",")
+}
+
+// usage prints the usage of the commands and the flags
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "  %s [flags]                       visualize resources\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "  %s snapshot [flags]              save resources to snapshot file specified with -outfile\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "  %s render -snapshot FILE [flags] visualize resources in snapshot file\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "Flags:\n")
+	flag.PrintDefaults()
+}
+
+// collect returns the resources in the namespaces decided from the flags
+// It exits on failure.
+func collect() []*resources.Resources {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// test connectivity for k8s cluster and the namespaces
+	namespaceList, err := getNamespaces(ctx, clientset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get namespaces: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Get all resources in the namespaces
+	collector := resources.NewCollector(clientset, collectorOptions())
+	ress := []*resources.Resources{}
+	for _, ns := range namespaceList {
+		res, err := collector.Collect(ctx, ns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get k8s resources: %v\n", err)
+			if strings.Contains(err.Error(), "the server could not find the requested resource") {
+				fmt.Fprintf(os.Stderr, "k8sviz 0.3.3 or later only support k8s 1.21 or later.\n")
+				fmt.Fprintf(os.Stderr, "If you are using older k8s cluster, try k8sviz 0.3.2 or earlier.\n")
+			}
 			os.Exit(1)
 		}
+		ress = append(ress, res)
+	}
+
+	return ress
+}
+
+// collectorOptions returns the options to get resources decided from the flags
+func collectorOptions() resources.Options {
+	return resources.Options{
+		DynamicClient:  dynamicClient,
+		MetadataClient: metadataClient,
+		ExtraKinds:     splitList(extraKinds),
+		LabelSelector:  selector,
+		FieldSelector:  fieldSelector,
+		SkipForbidden:  skipForbidden,
+		EndpointSlices: endpointSlices,
+		Storage:        storage,
+		PageSize:       pageSize,
+	}
+}
+
+// getClients returns the clientset, the dynamic client and the metadata client for the k8s cluster in kubeconfig,
+// or the offline ones for the manifests if -from-file is specified. The metadata client is nil for the manifests.
+func getClients(kubeconfig string) (kubernetes.Interface, dynamic.Interface, metadata.Interface, error) {
+	if fromFile != "" {
+		// Namespaced objects without namespace are put in the first namespace to visualize
+		defaultNs := namespace
+		if nss := splitList(namespaces); len(nss) > 0 {
+			defaultNs = nss[0]
+		}
+
+		objs, err := resources.LoadManifests(splitList(fromFile), defaultNs)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load manifests from %q: %v", fromFile, err)
+		}
+		return resources.NewOfflineClientset(objs), resources.NewOfflineDynamicClient(objs), nil, nil
+	}
+
+	// use the current context in kubeconfig
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to build config from %q: %v", kubeconfig, err)
+	}
+	// The name of the context is only recorded in snapshots, so it is fine to be unknown
+	if rawConfig, err := clientcmd.LoadFromFile(kubeconfig); err == nil {
+		kubeContext = rawConfig.CurrentContext
+	}
+
+	// create the clientset
+	cs, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create client from %q: %v", kubeconfig, err)
+	}
+
+	// create the dynamic client
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create dynamic client from %q: %v", kubeconfig, err)
+	}
+
+	// create the metadata client, to get secrets without their data
+	mc, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create metadata client from %q: %v", kubeconfig, err)
+	}
+	return cs, dc, mc, nil
+}
+
+// getNamespaces returns the namespaces to visualize decided from the flags.
+// It also checks that all the namespaces exist.
+func getNamespaces(ctx context.Context, cs kubernetes.Interface) ([]string, error) {
+	if allNamespaces {
+		nsList, err := cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %v", err)
+		}
+		nss := []string{}
+		for _, ns := range nsList.Items {
+			nss = append(nss, ns.Name)
+		}
+		return nss, nil
+	}
+
+	nss := []string{namespace}
+	if namespaces != "" {
+		nss = splitList(namespaces)
+	}
+
+	for _, ns := range nss {
+		if _, err := cs.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to get namespace %q: %v", ns, err)
+		}
+	}
+	return nss, nil
+}
+
+// splitList returns the non-empty elements of the comma separated list
+func splitList(list string) []string {
+	elems := []string{}
+	for _, elem := range strings.Split(list, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			elems = append(elems, elem)
+		}
 	}
+	return elems
 }
 
 func getBinDir() (string, error) {
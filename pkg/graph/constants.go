@@ -3,8 +3,56 @@
 
 package graph
 
+import (
+	"github.com/mkimuram/k8sviz/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+)
+
 const (
 	clusterPrefix = "cluster_"
 	rankPrefix    = "rank_"
 	imageSuffix   = "-128.png"
+	// extraIcon is the icon for extra resources, like CRD
+	extraIcon = "crd"
+	// nodeIcon is the icon for the clusters of k8s nodes
+	nodeIcon = "node"
+	// unscheduledName is the name of the cluster for pods that aren't scheduled to any node
+	unscheduledName = "unscheduled"
+)
+
+const (
+	// problemColor is the color for the resources with problems, like pods in CrashLoopBackOff
+	problemColor = "red"
+	// warningColor is the color for the resources that may be wrong, like statefulsets with non-headless services
+	warningColor = "orange"
 )
+
+// podStatusColors are the colors for the statuses of pods
+// Healthy pods are green, pods that are starting or not ready yet are orange,
+// pods with problems are red, and pods that finished or are being deleted are gray.
+var podStatusColors = map[resources.PodStatus]string{
+	resources.PodStatusRunning:          "darkgreen",
+	resources.PodStatusNotReady:         "orange",
+	resources.PodStatusPending:          "orange",
+	resources.PodStatusCrashLoopBackOff: problemColor,
+	resources.PodStatusImagePullBackOff: problemColor,
+	resources.PodStatusOOMKilled:        problemColor,
+	resources.PodStatusFailed:           problemColor,
+	resources.PodStatusCompleted:        "gray",
+	resources.PodStatusTerminating:      "gray",
+}
+
+// pvcPhaseColors are the colors for the phases of pvcs in the storage view
+var pvcPhaseColors = map[corev1.PersistentVolumeClaimPhase]string{
+	corev1.ClaimBound:   "darkgreen",
+	corev1.ClaimPending: "orange",
+	corev1.ClaimLost:    problemColor,
+}
+
+// accessModeNames are the short names of the access modes, like kubectl shows
+var accessModeNames = map[corev1.PersistentVolumeAccessMode]string{
+	corev1.ReadWriteOnce:    "RWO",
+	corev1.ReadOnlyMany:     "ROX",
+	corev1.ReadWriteMany:    "RWX",
+	corev1.ReadWriteOncePod: "RWOP",
+}
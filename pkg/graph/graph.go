@@ -8,23 +8,59 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 
 	"github.com/awalterschulze/gographviz"
 	"github.com/mkimuram/k8sviz/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // Graph represents a graph of k8s resources
 type Graph struct {
 	dir  string
-	res  *resources.Resources
+	ress []*resources.Resources
+	opts Options
 	gviz *gographviz.Graph
 }
 
+// Options represents the options to draw a graph
+type Options struct {
+	// Traffic draws the traffic between pods that network policies allow
+	Traffic bool
+	// EndpointSlices draws the edges of services to pods from EndpointSlices, instead of selectors
+	// Resources need to be collected with resources.Options.EndpointSlices.
+	EndpointSlices bool
+	// GroupByNode draws pods in the clusters for the k8s nodes that they are scheduled to
+	GroupByNode bool
+	// Storage draws the PersistentVolumes and the StorageClasses of pvcs, and the details of pvcs
+	// Resources need to be collected with resources.Options.Storage to show the details of them.
+	Storage bool
+	// Containers draws the init, regular and ephemeral containers in pods, with their images, ports and restart counts
+	Containers bool
+	// Images draws the images used by the pod templates of workloads, grouped by their registries
+	// Images whose versions drift, like the same repository at different tags, are outlined.
+	Images bool
+}
+
 // NewGraph returns a Graph of k8s resources
 func NewGraph(res *resources.Resources, dir string) *Graph {
-	g := &Graph{res: res, dir: dir, gviz: gographviz.NewGraph()}
+	return NewGraphForNamespaces([]*resources.Resources{res}, dir)
+}
+
+// NewGraphForNamespaces returns a Graph of k8s resources in multiple namespaces.
+// Each namespace is drawn as its own cluster with the same rank layout.
+func NewGraphForNamespaces(ress []*resources.Resources, dir string) *Graph {
+	return NewGraphWithOptions(ress, dir, Options{})
+}
+
+// NewGraphWithOptions returns a Graph of k8s resources in multiple namespaces with the options
+func NewGraphWithOptions(ress []*resources.Resources, dir string, opts Options) *Graph {
+	g := &Graph{ress: ress, dir: dir, opts: opts, gviz: gographviz.NewGraph()}
 	g.generate()
 
 	return g
@@ -113,13 +149,10 @@ func (g *Graph) generate() {
 
 // generateCommon generates the common part of the graph
 func (g *Graph) generateCommon() {
-	// Create digraph for namespace.
+	// Create digraph.
 	// ```
 	// digraph G {
 	//   rankdir=TD;
-	//   label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/ns-128.png" /></TD></TR><TR><TD>ns1</TD></TR></TABLE>>;
-	//   labeljust=l;
-	//   style=dotted;
 	// ```
 	err := g.gviz.SetDir(true)
 	if err != nil {
@@ -133,13 +166,29 @@ func (g *Graph) generateCommon() {
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to set rankdir to TD: %v\n", err)
 	}
-	err = g.gviz.AddSubGraph("G", g.clusterName(),
-		map[string]string{"label": g.clusterLabel(), "labeljust": "l", "style": "dotted"})
+
+	for _, res := range g.ress {
+		g.generateNamespace(res)
+	}
+}
+
+// generateNamespace generates the cluster and the ranks for the namespace of res
+func (g *Graph) generateNamespace(res *resources.Resources) {
+	// Create subgraph for namespace.
+	// ```
+	// subgraph cluster_ns1 {
+	//   label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/ns-128.png" /></TD></TR><TR><TD>ns1</TD></TR></TABLE>>;
+	//   labeljust=l;
+	//   style=dotted;
+	// ```
+	ns := res.Namespace
+	err := g.gviz.AddSubGraph("G", g.clusterName(ns),
+		map[string]string{"label": g.clusterLabel(ns, res.Invisible, g.policyRows(res)...), "labeljust": "l", "style": "dotted"})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to add subgraph %s to digraph G: %v\n", g.clusterName(), err)
+		fmt.Fprintf(os.Stderr, "Failed to add subgraph %s to digraph G: %v\n", g.clusterName(ns), err)
 	}
 
-	// Create subgraphs for resources to group by rank (repeats #ResourceTypes)
+	// Create subgraphs for resources to group by rank (repeats #ranks of the registered kinds)
 	// ```
 	// subgraph rank_0 {
 	// rank=same;
@@ -155,33 +204,34 @@ func (g *Graph) generateCommon() {
 	// }
 	// ;
 	// ```
-	for r := 0; r < len(resources.ResourceTypes); r++ {
-		err = g.gviz.AddSubGraph(g.clusterName(), g.rankName(r),
+	ranks := len(resources.Ranks())
+	for r := 0; r < ranks; r++ {
+		err = g.gviz.AddSubGraph(g.clusterName(ns), g.rankName(ns, r),
 			map[string]string{"rank": "same", "style": "invis"})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to add subgraph %s to subgraph %s: %v\n", g.rankName(r), g.clusterName(), err)
+			fmt.Fprintf(os.Stderr, "Failed to add subgraph %s to subgraph %s: %v\n", g.rankName(ns, r), g.clusterName(ns), err)
 		}
 
 		// Put dummy invisible node to order ranks
-		err = g.gviz.AddNode(g.rankName(r), g.rankDummyNodeName(r),
+		err = g.gviz.AddNode(g.rankName(ns, r), g.rankDummyNodeName(ns, r),
 			map[string]string{"style": "invis", "height": "0", "width": "0", "margin": "0"})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.rankDummyNodeName(r), g.rankName(r), err)
+			fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.rankDummyNodeName(ns, r), g.rankName(ns, r), err)
 		}
 	}
 
-	// Order ranks (repeats #ResourceTypes)
+	// Order ranks (repeats #ranks of the registered kinds)
 	// This will make the layout consistent.
 	// ```
 	// 0->1[ style=invis ];
 	// 1->2[ style=invis ];
 	// ```
-	for r := 0; r < len(resources.ResourceTypes)-1; r++ {
+	for r := 0; r < ranks-1; r++ {
 		// Connect rth node and r+1th dummy node with invisible edge
-		err = g.gviz.AddEdge(g.rankDummyNodeName(r), g.rankDummyNodeName(r+1), true,
+		err = g.gviz.AddEdge(g.rankDummyNodeName(ns, r), g.rankDummyNodeName(ns, r+1), true,
 			map[string]string{"style": "invis"})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.rankDummyNodeName(r), g.rankDummyNodeName(r+1), err)
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.rankDummyNodeName(ns, r), g.rankDummyNodeName(ns, r+1), err)
 		}
 	}
 }
@@ -189,52 +239,293 @@ func (g *Graph) generateCommon() {
 // generateNodes generates the nodes of the graph
 // K8s resources are represented as graph nodes in k8sviz.
 func (g *Graph) generateNodes() {
+	for _, res := range g.ress {
+		g.generateNamespaceNodes(res)
+	}
+	g.generateClusterNodes()
+	if g.opts.Images {
+		g.generateImageNodes()
+	}
+}
+
+// generateImageNodes generates the nodes for the images used by the workloads in the clusters for their registries
+func (g *Graph) generateImageNodes() {
+	// Create subgraph for registry, and put the images in it.
+	// ```
+	// subgraph cluster_registry_docker_io {
+	//   label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/registry-128.png" /></TD></TR><TR><TD>docker.io</TD></TR></TABLE>>;
+	//   labeljust=l;
+	//   style=dashed;
+	//   image_docker_io_library_nginx_1_25 [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/image-128.png" /></TD></TR><TR><TD>library/nginx:1.25</TD></TR></TABLE>>, penwidth=0 ];
+	// }
+	// ```
+	for _, usage := range resources.ImageInventory(g.ress) {
+		registry := g.registryClusterName(usage.Image.Registry)
+		if !g.gviz.IsSubGraph(registry) {
+			err := g.gviz.AddSubGraph("G", registry, map[string]string{"label": g.resourceLabel("registry", usage.Image.Registry), "labeljust": "l", "style": "dashed"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add subgraph %s to digraph G: %v\n", registry, err)
+			}
+		}
+
+		attrs := map[string]string{"label": g.imageLabel(usage), "penwidth": "0"}
+		if usage.Drifted() {
+			attrs["color"] = warningColor
+			attrs["penwidth"] = "2"
+			attrs["shape"] = "box"
+			attrs["style"] = "rounded"
+		}
+		err := g.gviz.AddNode(registry, g.imageName(usage.Image), attrs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.imageName(usage.Image), registry, err)
+		}
+	}
+}
+
+// generateClusterNodes generates the nodes for cluster-scoped resources referred from namespaces
+// They are put outside the clusters for namespaces, and shared across namespaces.
+// ```
+// c_role_my_clusterrole [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/c-role-128.png" /></TD></TR><TR><TD>my-clusterrole</TD></TR></TABLE>>, penwidth=0 ];
+// ingclass_my_ingressclass [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/ingclass-128.png" /></TD></TR><TR><TD>my-ingressclass</TD></TR></TABLE>>, penwidth=0 ];
+// pv_my_persistentvolume [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pv-128.png" /></TD></TR><TR><TD>my-persistentvolume</TD></TR><TR><TD>10Gi RWO</TD></TR><TR><TD>reclaim: Delete</TD></TR></TABLE>>, penwidth=0 ];
+// sc_my_storageclass [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/sc-128.png" /></TD></TR><TR><TD>my-storageclass</TD></TR><TR><TD>kubernetes.io/gce-pd</TD></TR></TABLE>>, penwidth=0 ];
+// ```
+func (g *Graph) generateClusterNodes() {
+	for _, res := range g.ress {
+		for _, ing := range res.Ingresses.Items {
+			class := resources.IngressClassName(&ing)
+			if class == "" || g.gviz.IsNode(g.clusterResourceName("ingclass", class)) {
+				continue
+			}
+			err := g.gviz.AddNode("G", g.clusterResourceName("ingclass", class),
+				map[string]string{"label": g.resourceLabel("ingclass", class), "penwidth": "0"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add node %s to digraph G: %v\n", g.clusterResourceName("ingclass", class), err)
+			}
+		}
+
+		for _, rb := range res.Rbs.Items {
+			if rb.RoleRef.Kind != "ClusterRole" || g.gviz.IsNode(g.clusterResourceName("c-role", rb.RoleRef.Name)) {
+				continue
+			}
+			err := g.gviz.AddNode("G", g.clusterResourceName("c-role", rb.RoleRef.Name),
+				map[string]string{"label": g.resourceLabel("c-role", rb.RoleRef.Name), "penwidth": "0"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add node %s to digraph G: %v\n", g.clusterResourceName("c-role", rb.RoleRef.Name), err)
+			}
+		}
+
+		if g.opts.Storage {
+			g.generateStorageNodes(res)
+		}
+	}
+}
+
+// generateStorageNodes generates the nodes for the PersistentVolumes and the StorageClasses of the pvcs in res
+// Volumes and classes that aren't collected, like forbidden ones, are drawn only with their names.
+func (g *Graph) generateStorageNodes(res *resources.Resources) {
+	for i, pvc := range res.Pvcs.Items {
+		pv := res.BoundPv(&res.Pvcs.Items[i])
+		if pvc.Spec.VolumeName != "" && !g.gviz.IsNode(g.clusterResourceName("pv", pvc.Spec.VolumeName)) {
+			label := g.resourceLabel("pv", pvc.Spec.VolumeName)
+			if pv != nil {
+				label = g.pvLabel(pv)
+			}
+			g.addClusterNode("pv", pvc.Spec.VolumeName, label)
+		}
+
+		class := pvcStorageClass(&res.Pvcs.Items[i], pv)
+		if class != "" && !g.gviz.IsNode(g.clusterResourceName("sc", class)) {
+			label := g.resourceLabel("sc", class)
+			if sc := res.StorageClass(class); sc != nil {
+				label = g.scLabel(sc)
+			}
+			g.addClusterNode("sc", class, label)
+		}
+	}
+}
+
+// addClusterNode adds the node for the cluster-scoped resource to digraph G
+func (g *Graph) addClusterNode(kind, name, label string) {
+	err := g.gviz.AddNode("G", g.clusterResourceName(kind, name), map[string]string{"label": label, "penwidth": "0"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to add node %s to digraph G: %v\n", g.clusterResourceName(kind, name), err)
+	}
+}
+
+// pvcStorageClass returns the name of the StorageClass of the pvc, or the one of its volume if the pvc doesn't have it
+func pvcStorageClass(pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) string {
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		return *pvc.Spec.StorageClassName
+	}
+	if pv != nil {
+		return pv.Spec.StorageClassName
+	}
+	return ""
+}
+
+// generateNamespaceNodes generates the nodes of the graph for the namespace of res
+func (g *Graph) generateNamespaceNodes(res *resources.Resources) {
 	// Create graphviz nodes for k8s resources like below.
 	// ```
 	// pod_my_pod [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pod-128.png" /></TD></TR><TR><TD>my-pod</TD></TR></TABLE>>, penwidth=0 ];
 	// ```
+	// Pods are drawn with their statuses, and the ones with problems are outlined in red.
+	// ```
+	// pod_my_pod [ color=red, label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pod-128.png" /></TD></TR><TR><TD>my-pod</TD></TR><TR><TD><FONT COLOR="red">CrashLoopBackOff</FONT></TD></TR></TABLE>>, penwidth=2, shape=box, style=rounded ];
+	// ```
 	// Each resource is created in the subgraph of the rank for its resource types,
 	// so that the same resource types are placed in the same rank.
-	for r, rankRes := range resources.ResourceTypes {
-		for _, resType := range strings.Fields(rankRes) {
-			for _, name := range g.res.GetResourceNames(resType) {
-				err := g.gviz.AddNode(g.rankName(r), g.resourceName(resType, name),
-					map[string]string{"label": g.resourceLabel(resType, name), "penwidth": "0"})
+	ns := res.Namespace
+	for r, kinds := range resources.Ranks() {
+		for _, resType := range kinds {
+			if resType == "pod" && g.opts.GroupByNode {
+				g.generatePodsByNode(res)
+				continue
+			}
+			for _, name := range res.GetResourceNames(resType) {
+				err := g.gviz.AddNode(g.rankName(ns, r), g.resourceName(ns, resType, name), g.nodeAttrs(res, resType, name))
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.resourceName(resType, name), g.rankName(r), err)
+					fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.resourceName(ns, resType, name), g.rankName(ns, r), err)
 				}
 			}
 		}
 	}
+
+	// Extra resources, like CRD, are put directly in the cluster for namespace,
+	// so that they are placed by their relations to other resources.
+	// ```
+	// rollout_argoproj_io_my_rollout [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/crd-128.png" /></TD></TR><TR><TD>Rollout</TD></TR><TR><TD>my-rollout</TD></TR></TABLE>>, penwidth=0 ];
+	// ```
+	for _, extra := range res.Extras {
+		for _, name := range res.GetResourceNames(extra.Name()) {
+			err := g.gviz.AddNode(g.clusterName(ns), g.resourceName(ns, extra.Name(), name),
+				map[string]string{"label": g.extraResourceLabel(extra.Kind, name), "penwidth": "0"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.resourceName(ns, extra.Name(), name), g.clusterName(ns), err)
+			}
+		}
+	}
+}
+
+// generatePodsByNode generates the nodes for pods in the clusters for the k8s nodes that they are scheduled to
+func (g *Graph) generatePodsByNode(res *resources.Resources) {
+	// Create subgraph for each k8s node in the cluster for namespace, and put pods in it.
+	// Pods that aren't scheduled yet, like pending ones, are put in the cluster for unscheduled.
+	// ```
+	// subgraph cluster_node_my_node {
+	//   label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/node-128.png" /></TD></TR><TR><TD>my-node</TD></TR></TABLE>>;
+	//   labeljust=l;
+	//   style=dashed;
+	//   pod_my_pod [ label=<<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pod-128.png" /></TD></TR><TR><TD>my-pod</TD></TR></TABLE>>, penwidth=0 ];
+	// }
+	// ```
+	ns := res.Namespace
+	podsByNode := map[string][]string{}
+	for _, pod := range res.Pods.Items {
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod.Name)
+	}
+	nodes := []string{}
+	for node := range podsByNode {
+		if node != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	if _, ok := podsByNode[""]; ok {
+		nodes = append(nodes, "")
+	}
+
+	for _, node := range nodes {
+		label := g.resourceLabel(nodeIcon, node)
+		if node == "" {
+			label = g.resourceLabel(nodeIcon, unscheduledName)
+		}
+		err := g.gviz.AddSubGraph(g.clusterName(ns), g.nodeClusterName(ns, node),
+			map[string]string{"label": label, "labeljust": "l", "style": "dashed"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add subgraph %s to subgraph %s: %v\n", g.nodeClusterName(ns, node), g.clusterName(ns), err)
+		}
+
+		for _, name := range podsByNode[node] {
+			err := g.gviz.AddNode(g.nodeClusterName(ns, node), g.resourceName(ns, "pod", name), g.nodeAttrs(res, "pod", name))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add node %s to subgraph %s: %v\n", g.resourceName(ns, "pod", name), g.nodeClusterName(ns, node), err)
+			}
+		}
+	}
 }
 
 // generateEdges generates the edges of the graph
 // Relations between k8s resources are represented as graph edges in k8sviz.
 func (g *Graph) generateEdges() {
-	// Owner reference for pod
-	g.genPodOwnerRef()
+	for _, res := range g.ress {
+		// Owner reference for pod
+		g.genPodOwnerRef(res)
 
-	// Owner reference for rs
-	g.genRsOwnerRef()
+		// Owner reference for rs
+		g.genRsOwnerRef(res)
 
-	// Owner reference for job
-	g.genJobOwnerRef()
+		// Owner reference for job
+		g.genJobOwnerRef(res)
 
-	// hpa to scale target
-	g.genHpaScaleTargetRef()
+		// Owner reference for deploy, sts and ds
+		g.genWorkloadOwnerRef(res)
 
-	// pvc and pod
-	g.genPvcPodRef()
+		// Owner reference for extra resources
+		g.genExtraOwnerRef(res)
 
-	// svc and pod
-	g.genSvcPodRef()
+		// hpa to scale target
+		g.genHpaScaleTargetRef(res)
+		g.genHpaMetricRef(res)
+
+		// pvc and pod
+		g.genPvcPodRef(res)
+		if g.opts.Storage {
+			g.genPvcStorageRef(res)
+		}
+
+		// statefulset, pvc and svc
+		g.genStsRef(res)
+		g.genConfigPodRef(res)
+		g.genSaPodRef(res)
+		g.genRbRef(res)
+
+		// svc and pod
+		if g.opts.EndpointSlices {
+			g.genSvcEndpointRef(res)
+		} else {
+			g.genSvcPodRef(res)
+		}
 
-	// ingress and svc
-	g.genIngSvcRef()
+		// ingress and svc, secret and ingressclass
+		g.genIngSvcRef(res)
+		g.genIngTLSRef(res)
+		g.genIngClassRef(res)
+
+		// gateway api routes, gateway and svc
+		g.genRouteRef(res)
+
+		// networkpolicy and pod
+		g.genNetpolPodRef(res)
+
+		// poddisruptionbudget and pod
+		g.genPdbPodRef(res)
+	}
+
+	// traffic between pods, which can be across namespaces
+	if g.opts.Traffic {
+		g.genTraffic()
+	}
+
+	// images used by workloads, which can be shared across namespaces
+	if g.opts.Images {
+		g.genImageRef()
+	}
 }
 
 // genPodOwnerRef generates the edges of OwnerReferences from Pod
-func (g *Graph) genPodOwnerRef() {
+func (g *Graph) genPodOwnerRef(res *resources.Resources) {
 	// Add edge if below matches:
 	//   - v1.Pod.metadata.ownerReferences.
 	//     - kind
@@ -243,13 +534,13 @@ func (g *Graph) genPodOwnerRef() {
 	// ```
 	// rs_my_replicaset->pod_my_pod [ style=dashed ];
 	// ```
-	for _, pod := range g.res.Pods.Items {
-		g.genOwnerRef("pod", &pod)
+	for _, pod := range res.Pods.Items {
+		g.genOwnerRef(res, "pod", &pod)
 	}
 }
 
 // genRsOwnerRef generates the edges of OwnerReferences from RS
-func (g *Graph) genRsOwnerRef() {
+func (g *Graph) genRsOwnerRef(res *resources.Resources) {
 	// Add edge if below matches:
 	//   - apps/v1.ReplicaSet.metadata.ownerReferences.
 	//     - kind
@@ -258,13 +549,13 @@ func (g *Graph) genRsOwnerRef() {
 	// ```
 	// deploy_my_deployment->rs_my_replicaset[ style=dashed ];
 	// ```
-	for _, rs := range g.res.Rss.Items {
-		g.genOwnerRef("rs", &rs)
+	for _, rs := range res.Rss.Items {
+		g.genOwnerRef(res, "rs", &rs)
 	}
 }
 
 // genJobOwnerRef generates the edges of OwnerReferences from job
-func (g *Graph) genJobOwnerRef() {
+func (g *Graph) genJobOwnerRef(res *resources.Resources) {
 	// Add edge if below matches:
 	//   - batch/v1.Job.metadata.ownerReferences.
 	//     - kind
@@ -273,80 +564,157 @@ func (g *Graph) genJobOwnerRef() {
 	// ```
 	// cronjob_my_cronjob->job_my_job[ style=dashed ];
 	// ```
-	for _, job := range g.res.Jobs.Items {
-		g.genOwnerRef("job", &job)
+	for _, job := range res.Jobs.Items {
+		g.genOwnerRef(res, "job", &job)
+	}
+}
+
+// genWorkloadOwnerRef generates the edges of OwnerReferences from deploy, sts and ds
+func (g *Graph) genWorkloadOwnerRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - apps/v1.{Deployment,StatefulSet,DaemonSet}.metadata.ownerReferences.
+	//     - kind
+	//     - name
+	//   - {kind}.metadata.{name}
+	// They are usually owned by extra resources, like CRD for operators.
+	// ```
+	// kafka_kafka_strimzi_io_my_kafka->sts_my_statefulset[ style=dashed ];
+	// ```
+	for _, deploy := range res.Deploys.Items {
+		g.genOwnerRef(res, "deploy", &deploy)
+	}
+	for _, sts := range res.Stss.Items {
+		g.genOwnerRef(res, "sts", &sts)
+	}
+	for _, ds := range res.Dss.Items {
+		g.genOwnerRef(res, "ds", &ds)
+	}
+}
+
+// genExtraOwnerRef generates the edges of OwnerReferences from extra resources
+func (g *Graph) genExtraOwnerRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - {extra kind}.metadata.ownerReferences.
+	//     - kind
+	//     - name
+	//   - {kind}.metadata.{name}
+	// ```
+	// kafka_kafka_strimzi_io_my_kafka->kafkanodepool_kafka_strimzi_io_my_pool[ style=dashed ];
+	// ```
+	for _, extra := range res.Extras {
+		for i := range extra.List.Items {
+			g.genOwnerRef(res, extra.Name(), &extra.List.Items[i])
+		}
 	}
 }
 
 // genOwnerRef generates the edges of OwnerReferences for specified obj
-func (g *Graph) genOwnerRef(kind string, obj metav1.Object) {
+func (g *Graph) genOwnerRef(res *resources.Resources, kind string, obj metav1.Object) {
+	ns := res.Namespace
 	for _, ref := range obj.GetOwnerReferences() {
-		ownerKind, err := resources.NormalizeResource(ref.Kind)
+		ownerKind, err := res.NormalizeKind(ref.APIVersion, ref.Kind)
 		if err != nil {
-			// Skip resource that isn't available for this tool, like CRD
+			// Skip resource that isn't available for this tool, like CRD not in Extras
 			continue
 		}
-		if !g.res.HasResource(ownerKind, ref.Name) {
+		if !res.HasResource(ownerKind, ref.Name) {
 			fmt.Fprintf(os.Stderr, "%s %s not found as a owner refernce for rs %s\n", ownerKind, ref.Name, obj.GetName())
 			continue
 		}
 
-		err = g.gviz.AddEdge(g.resourceName(ownerKind, ref.Name), g.resourceName(kind, obj.GetName()), true,
+		err = g.gviz.AddEdge(g.resourceName(ns, ownerKind, ref.Name), g.resourceName(ns, kind, obj.GetName()), true,
 			map[string]string{"style": "dashed"})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ownerKind, ref.Name), g.resourceName(kind, obj.GetName()), err)
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, ownerKind, ref.Name), g.resourceName(ns, kind, obj.GetName()), err)
 		}
 	}
 }
 
 // genHpaScaleTargetRef generates the edges of HPA to deploy reference
-func (g *Graph) genHpaScaleTargetRef() {
+func (g *Graph) genHpaScaleTargetRef(res *resources.Resources) {
 	// Add edge if below matches:
-	//   - k8s.io/api/autoscaling/v1.HorizontalPodAutoscaler.Spec.ScaleTargetRef.
+	//   - k8s.io/api/autoscaling/v2.HorizontalPodAutoscaler.Spec.ScaleTargetRef.
 	//     - kind
 	//     - name
 	//   - {kind}.metadata.{name}
 	// ```
 	// hpa_my_hpa->deploy_my_deploy[ style=dashed ];
 	// ```
-	for _, hpa := range g.res.Hpas.Items {
+	ns := res.Namespace
+	for _, hpa := range res.Hpas.Items {
 		target := hpa.Spec.ScaleTargetRef
-		targetKind, err := resources.NormalizeResource(target.Kind)
+		targetKind, err := res.NormalizeKind(target.APIVersion, target.Kind)
 		if err != nil {
-			// Skip resource that isn't available for this tool, like CRD
+			// Skip resource that isn't available for this tool, like CRD not in Extras
 			continue
 		}
-		if !g.res.HasResource(targetKind, target.Name) {
+		if !res.HasResource(targetKind, target.Name) {
 			fmt.Fprintf(os.Stderr, "%s %q is referenced from %q, but not found\n", targetKind, target.Name, hpa.Name)
 			continue
 		}
 
-		err = g.gviz.AddEdge(g.resourceName("hpa", hpa.Name), g.resourceName(targetKind, target.Name), true, map[string]string{"style": "dashed"})
+		err = g.gviz.AddEdge(g.resourceName(ns, "hpa", hpa.Name), g.resourceName(ns, targetKind, target.Name), true, map[string]string{"style": "dashed"})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName("hpa", hpa.Name), g.resourceName(targetKind, target.Name), err)
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "hpa", hpa.Name), g.resourceName(ns, targetKind, target.Name), err)
+		}
+	}
+}
+
+// genHpaMetricRef generates the edges of HorizontalPodAutoscaler to the objects that its object metrics describe
+func (g *Graph) genHpaMetricRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - k8s.io/api/autoscaling/v2.HorizontalPodAutoscaler.Spec.Metrics[].Object.DescribedObject.
+	//     - kind
+	//     - name
+	//   - {kind}.metadata.{name}
+	// ```
+	// hpa_my_hpa->ing_my_ingress[ style=dotted ];
+	// ```
+	ns := res.Namespace
+	for _, hpa := range res.Hpas.Items {
+		for _, metric := range hpa.Spec.Metrics {
+			if metric.Object == nil {
+				continue
+			}
+			obj := metric.Object.DescribedObject
+			objKind, err := res.NormalizeKind(obj.APIVersion, obj.Kind)
+			if err != nil {
+				// Skip resource that isn't available for this tool, like CRD not in Extras
+				continue
+			}
+			if !res.HasResource(objKind, obj.Name) {
+				fmt.Fprintf(os.Stderr, "%s %q is referenced from %q, but not found\n", objKind, obj.Name, hpa.Name)
+				continue
+			}
+
+			err = g.gviz.AddEdge(g.resourceName(ns, "hpa", hpa.Name), g.resourceName(ns, objKind, obj.Name), true, map[string]string{"style": "dotted"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "hpa", hpa.Name), g.resourceName(ns, objKind, obj.Name), err)
+			}
 		}
 	}
 }
 
 // genPvcPodRef generates the edges of PVC to Pod reference
-func (g *Graph) genPvcPodRef() {
+func (g *Graph) genPvcPodRef(res *resources.Resources) {
 	// Add edge if below matches:
 	//   - v1.Pod.spec.volumes[].persistentVolumeClaim.claimName
 	//   - v1.PersistentVolumeClaim.metadata.name
 	// ```
 	// pod_my_pod->pvc_my_persistentvolumeclaim[ dir=none ];
 	// ```
-	for _, pod := range g.res.Pods.Items {
+	ns := res.Namespace
+	for _, pod := range res.Pods.Items {
 		for _, vol := range pod.Spec.Volumes {
 			if vol.VolumeSource.PersistentVolumeClaim != nil {
-				if !g.res.HasResource("pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName) {
+				if !res.HasResource("pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName) {
 					fmt.Fprintf(os.Stderr, "pvc %s not found as a volume for pod %s\n", vol.VolumeSource.PersistentVolumeClaim.ClaimName, pod.Name)
 					continue
 				}
 
-				err := g.gviz.AddEdge(g.resourceName("pod", pod.Name), g.resourceName("pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName), true, map[string]string{"dir": "none"})
+				err := g.gviz.AddEdge(g.resourceName(ns, "pod", pod.Name), g.resourceName(ns, "pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName), true, map[string]string{"dir": "none"})
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName("pod", pod.Name), g.resourceName("pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName), err)
+					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", pod.Name), g.resourceName(ns, "pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName), err)
 				}
 
 			}
@@ -354,59 +722,537 @@ func (g *Graph) genPvcPodRef() {
 	}
 }
 
+// genPvcStorageRef generates the edges of PVC to PersistentVolume and StorageClass reference
+func (g *Graph) genPvcStorageRef(res *resources.Resources) {
+	// Add edge if below matches, which are drawn with the nodes for the PersistentVolumes and the StorageClasses:
+	//   - v1.PersistentVolumeClaim.spec.volumeName
+	//   - v1.PersistentVolume.metadata.name
+	// and the StorageClass of the PersistentVolume, or the one of the pvc if it isn't bound yet
+	//   - v1.PersistentVolume.spec.storageClassName or v1.PersistentVolumeClaim.spec.storageClassName
+	//   - storage.k8s.io/v1.StorageClass.metadata.name
+	// ```
+	// pvc_my_persistentvolumeclaim->pv_my_persistentvolume[ dir=none ];
+	// pv_my_persistentvolume->sc_my_storageclass[ dir=none, style=dashed ];
+	// pvc_my_pending_persistentvolumeclaim->sc_my_storageclass[ dir=none, style=dashed ];
+	// ```
+	ns := res.Namespace
+	for i, pvc := range res.Pvcs.Items {
+		pv := res.BoundPv(&res.Pvcs.Items[i])
+		class := pvcStorageClass(&res.Pvcs.Items[i], pv)
+		pvcName := g.resourceName(ns, "pvc", pvc.Name)
+		if pvc.Spec.VolumeName == "" {
+			// The pvc is waiting for the volume to be provisioned by the class
+			if class != "" {
+				err := g.gviz.AddEdge(pvcName, g.clusterResourceName("sc", class), true, map[string]string{"dir": "none", "style": "dashed"})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", pvcName, g.clusterResourceName("sc", class), err)
+				}
+			}
+			continue
+		}
+
+		pvName := g.clusterResourceName("pv", pvc.Spec.VolumeName)
+		err := g.gviz.AddEdge(pvcName, pvName, true, map[string]string{"dir": "none"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", pvcName, pvName, err)
+		}
+		if class != "" {
+			err := g.gviz.AddEdge(pvName, g.clusterResourceName("sc", class), true, map[string]string{"dir": "none", "style": "dashed"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", pvName, g.clusterResourceName("sc", class), err)
+			}
+		}
+	}
+}
+
+// genStsRef generates the edges of StatefulSet to PVC and Service reference
+func (g *Graph) genStsRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - apps/v1.StatefulSet.spec.volumeClaimTemplates[].metadata.name, apps/v1.StatefulSet.metadata.name and ordinals
+	//   - v1.PersistentVolumeClaim.metadata.name, like {template}-{statefulset}-{ordinal}
+	// and below matches:
+	//   - apps/v1.StatefulSet.spec.serviceName
+	//   - v1.Service.metadata.name
+	// ```
+	// sts_my_statefulset->pvc_data_my_statefulset_0[ style=dashed ];
+	// sts_my_statefulset->svc_my_service[ dir=none, style=dotted ];
+	// ```
+	// Statefulsets whose governing services are missing are outlined as problems by nodeAttrs.
+	ns := res.Namespace
+	for i, sts := range res.Stss.Items {
+		for _, pvc := range res.TemplateClaimNames(&res.Stss.Items[i]) {
+			err := g.gviz.AddEdge(g.resourceName(ns, "sts", sts.Name), g.resourceName(ns, "pvc", pvc), true, map[string]string{"style": "dashed"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "sts", sts.Name), g.resourceName(ns, "pvc", pvc), err)
+			}
+		}
+
+		if sts.Spec.ServiceName == "" || !res.HasResource("svc", sts.Spec.ServiceName) {
+			continue
+		}
+		err := g.gviz.AddEdge(g.resourceName(ns, "sts", sts.Name), g.resourceName(ns, "svc", sts.Spec.ServiceName), true, map[string]string{"dir": "none", "style": "dotted"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "sts", sts.Name), g.resourceName(ns, "svc", sts.Spec.ServiceName), err)
+		}
+	}
+}
+
+// genConfigPodRef generates the edges of Pod to ConfigMap and Secret reference
+func (g *Graph) genConfigPodRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - v1.Pod.spec.volumes[].configMap.name, v1.Pod.spec.volumes[].projected.sources[].configMap.name,
+	//     v1.Pod.spec.containers[].env[].valueFrom.configMapKeyRef.name or v1.Pod.spec.containers[].envFrom[].configMapRef.name
+	//   - v1.ConfigMap.metadata.name
+	// and the same for secrets, including v1.Pod.spec.imagePullSecrets[].name
+	// ```
+	// pod_my_pod->cm_my_configmap[ dir=none, style=dotted ];
+	// pod_my_pod->secret_my_secret[ dir=none, style=dotted ];
+	// ```
+	// Configmaps and secrets that aren't found, like optional ones, are ignored.
+	ns := res.Namespace
+	for _, pod := range res.Pods.Items {
+		refs := map[string][]string{
+			"cm":     resources.ConfigMapNames(&pod.Spec),
+			"secret": resources.SecretNames(&pod.Spec),
+		}
+		for _, kind := range []string{"cm", "secret"} {
+			for _, name := range refs[kind] {
+				if !res.HasResource(kind, name) {
+					continue
+				}
+
+				err := g.gviz.AddEdge(g.resourceName(ns, "pod", pod.Name), g.resourceName(ns, kind, name), true, map[string]string{"dir": "none", "style": "dotted"})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", pod.Name), g.resourceName(ns, kind, name), err)
+				}
+			}
+		}
+	}
+}
+
+// genSaPodRef generates the edges of Pod to ServiceAccount reference
+func (g *Graph) genSaPodRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - v1.Pod.spec.serviceAccountName ("default" if not specified)
+	//   - v1.ServiceAccount.metadata.name
+	// ```
+	// pod_my_pod->sa_my_serviceaccount[ dir=none, style=dotted ];
+	// ```
+	ns := res.Namespace
+	for _, pod := range res.Pods.Items {
+		name := resources.ServiceAccountName(&pod.Spec)
+		if !res.HasResource("sa", name) {
+			continue
+		}
+
+		err := g.gviz.AddEdge(g.resourceName(ns, "pod", pod.Name), g.resourceName(ns, "sa", name), true, map[string]string{"dir": "none", "style": "dotted"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", pod.Name), g.resourceName(ns, "sa", name), err)
+		}
+	}
+}
+
+// genRbRef generates the edges of RoleBinding to Role, ClusterRole and ServiceAccount reference
+func (g *Graph) genRbRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - v1.RoleBinding.roleRef.name
+	//   - v1.Role.metadata.name, or v1.ClusterRole.metadata.name
+	// ```
+	// rb_my_rolebinding->role_my_role;
+	// rb_my_rolebinding->c_role_my_clusterrole;
+	// ```
+	// and if below matches:
+	//   - v1.RoleBinding.subjects[].name and v1.RoleBinding.subjects[].namespace
+	//   - v1.ServiceAccount.metadata.name and v1.ServiceAccount.metadata.namespace
+	// ```
+	// sa_my_serviceaccount->rb_my_rolebinding[ dir=back ];
+	// ```
+	// Serviceaccounts in other namespaces are also linked, if they are in the graph.
+	ns := res.Namespace
+	for _, rb := range res.Rbs.Items {
+		switch rb.RoleRef.Kind {
+		case "Role":
+			if res.HasResource("role", rb.RoleRef.Name) {
+				err := g.gviz.AddEdge(g.resourceName(ns, "rb", rb.Name), g.resourceName(ns, "role", rb.RoleRef.Name), true, nil)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "rb", rb.Name), g.resourceName(ns, "role", rb.RoleRef.Name), err)
+				}
+			}
+		case "ClusterRole":
+			err := g.gviz.AddEdge(g.resourceName(ns, "rb", rb.Name), g.clusterResourceName("c-role", rb.RoleRef.Name), true, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "rb", rb.Name), g.clusterResourceName("c-role", rb.RoleRef.Name), err)
+			}
+		}
+
+		for _, subject := range rb.Subjects {
+			if subject.Kind != "ServiceAccount" {
+				continue
+			}
+			subjectNs := subject.Namespace
+			if subjectNs == "" {
+				subjectNs = ns
+			}
+			subjectRes := g.namespaceResources(subjectNs)
+			if subjectRes == nil || !subjectRes.HasResource("sa", subject.Name) {
+				continue
+			}
+
+			err := g.gviz.AddEdge(g.resourceName(subjectNs, "sa", subject.Name), g.resourceName(ns, "rb", rb.Name), true, map[string]string{"dir": "back"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(subjectNs, "sa", subject.Name), g.resourceName(ns, "rb", rb.Name), err)
+			}
+		}
+	}
+}
+
 // genSvcPodRef generates the edges of Service to Pod reference
-func (g *Graph) genSvcPodRef() {
+func (g *Graph) genSvcPodRef(res *resources.Resources) {
 	// Add edge if below matches:
 	//   - v1.Service.spec.selector
 	//   - v1.Pod.metadata.labels
 	// ```
 	// pod_my_pod->svc_my_service[ dir=back ];
 	// ```
-	for _, svc := range g.res.Svcs.Items {
+	ns := res.Namespace
+	for _, svc := range res.Svcs.Items {
+		// Services without selector don't select any pods
 		if len(svc.Spec.Selector) == 0 {
 			continue
 		}
-		// Check if pod has all labels specified in svc.Spec.Selector
-		for _, pod := range g.res.Pods.Items {
-			podLabel := pod.GetLabels()
-			matched := true
-			for selKey, selVal := range svc.Spec.Selector {
-				val, ok := podLabel[selKey]
-				if !ok || selVal != val {
-					matched = false
-					break
-				}
+		for _, pod := range res.PodIndex().MatchLabels(svc.Spec.Selector) {
+			err := g.gviz.AddEdge(g.resourceName(ns, "pod", pod), g.resourceName(ns, "svc", svc.Name), true, map[string]string{"dir": "back"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", pod), g.resourceName(ns, "svc", svc.Name), err)
 			}
+		}
+	}
+}
 
-			if matched {
-				err := g.gviz.AddEdge(g.resourceName("pod", pod.Name), g.resourceName("svc", svc.Name), true, map[string]string{"dir": "back"})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName("pod", pod.Name), g.resourceName("svc", svc.Name), err)
-				}
+// genSvcEndpointRef generates the edges of Service to Pod reference from EndpointSlices
+func (g *Graph) genSvcEndpointRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - discovery.k8s.io/v1.EndpointSlice.metadata.labels["kubernetes.io/service-name"]
+	//   - v1.Service.metadata.name
+	// and below matches:
+	//   - discovery.k8s.io/v1.EndpointSlice.endpoints[].targetRef
+	//   - v1.Pod.metadata.name
+	// ```
+	// pod_my_pod->svc_my_service[ dir=back ];
+	// ```
+	// Endpoints that aren't ready, like terminating ones, are drawn with a different style.
+	// ```
+	// pod_my_pod->svc_my_service[ color=gray, dir=back, style=dashed ];
+	// ```
+	ns := res.Namespace
+	added := map[string]bool{}
+	for _, slice := range res.EndpointSlices.Items {
+		svc := slice.Labels[discoveryv1.LabelServiceName]
+		if !res.HasResource("svc", svc) {
+			continue
+		}
+		for _, ep := range slice.Endpoints {
+			if ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" || !res.HasResource("pod", ep.TargetRef.Name) {
+				continue
+			}
+			// Same endpoint can be in multiple slices, like for IPv4 and IPv6
+			key := ep.TargetRef.Name + "/" + svc
+			if added[key] {
+				continue
+			}
+			added[key] = true
+
+			attrs := map[string]string{"dir": "back"}
+			// nil means unknown, which should be interpreted as ready
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				attrs["style"] = "dashed"
+				attrs["color"] = "gray"
+			}
+			err := g.gviz.AddEdge(g.resourceName(ns, "pod", ep.TargetRef.Name), g.resourceName(ns, "svc", svc), true, attrs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", ep.TargetRef.Name), g.resourceName(ns, "svc", svc), err)
+			}
+		}
+	}
+}
+
+// genNetpolPodRef generates the edges of NetworkPolicy to Pod reference
+func (g *Graph) genNetpolPodRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - networking.k8s.io/v1.NetworkPolicy.spec.podSelector
+	//   - v1.Pod.metadata.labels
+	// ```
+	// pod_my_pod->netpol_my_networkpolicy[ dir=back, style=dotted ];
+	// ```
+	ns := res.Namespace
+	for i, np := range res.Netpols.Items {
+		for _, pod := range res.SelectedPods(&res.Netpols.Items[i]) {
+			err := g.gviz.AddEdge(g.resourceName(ns, "pod", pod), g.resourceName(ns, "netpol", np.Name), true, map[string]string{"dir": "back", "style": "dotted"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", pod), g.resourceName(ns, "netpol", np.Name), err)
+			}
+		}
+	}
+}
+
+// genPdbPodRef generates the edges of PodDisruptionBudget to Pod reference
+func (g *Graph) genPdbPodRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - policy/v1.PodDisruptionBudget.spec.selector
+	//   - v1.Pod.metadata.labels
+	// ```
+	// pod_my_pod->pdb_my_pdb[ dir=back, style=dotted ];
+	// ```
+	ns := res.Namespace
+	for i, pdb := range res.Pdbs.Items {
+		for _, pod := range res.ProtectedPods(&res.Pdbs.Items[i]) {
+			err := g.gviz.AddEdge(g.resourceName(ns, "pod", pod), g.resourceName(ns, "pdb", pdb.Name), true, map[string]string{"dir": "back", "style": "dotted"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "pod", pod), g.resourceName(ns, "pdb", pdb.Name), err)
 			}
 		}
 	}
 }
 
-// genIngSvcRef generates the edges of Ingress to Service reference
-func (g *Graph) genIngSvcRef() {
+// genImageRef generates the edges of workloads to the images that their pod templates use
+func (g *Graph) genImageRef() {
 	// Add edge if below matches:
-	//   - networking.k8s.io/v1.Ingress.spec.rules.HTTP.paths[].backend.service.name
+	//   - apps/v1.Deployment.spec.template.spec.containers[].image, and the same for init containers,
+	//     statefulsets, daemonsets and the job templates of cronjobs
+	//   - the image
+	// ```
+	// deploy_my_deploy->image_docker_io_library_nginx_1_25[ style=dotted ];
+	// ```
+	for _, usage := range resources.ImageInventory(g.ress) {
+		for _, w := range usage.Workloads {
+			err := g.gviz.AddEdge(g.resourceName(w.Namespace, w.Kind, w.Name), g.imageName(usage.Image), true, map[string]string{"style": "dotted"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(w.Namespace, w.Kind, w.Name), g.imageName(usage.Image), err)
+			}
+		}
+	}
+}
+
+// genTraffic generates the edges of the traffic between pods that network policies allow
+func (g *Graph) genTraffic() {
+	// Add edge if network policies for both pods allow the traffic:
+	//   - networking.k8s.io/v1.NetworkPolicy.spec.egress[].to[] of the policies for the source pod
+	//   - networking.k8s.io/v1.NetworkPolicy.spec.ingress[].from[] of the policies for the destination pod
+	// ```
+	// pod_my_pod1->pod_my_pod2[ color=blue, constraint=false ];
+	// ```
+	// Traffic between pods that no policy isolates isn't drawn, because it's always allowed.
+	for _, t := range resources.AllowedTraffic(g.ress) {
+		err := g.gviz.AddEdge(g.resourceName(t.FromNamespace, "pod", t.From), g.resourceName(t.ToNamespace, "pod", t.To), true, map[string]string{"color": "blue", "constraint": "false"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(t.FromNamespace, "pod", t.From), g.resourceName(t.ToNamespace, "pod", t.To), err)
+		}
+	}
+}
+
+// genIngSvcRef generates the edges of Ingress to the backends, like Service
+func (g *Graph) genIngSvcRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - networking.k8s.io/v1.Ingress.spec.defaultBackend.service.name
+	//   - networking.k8s.io/v1.Ingress.spec.rules[].http.paths[].backend.service.name
 	//   - v1.Service.metadata.name
+	// Edges are labeled with the hosts and the paths, where "*" is for all hosts and "default" is for defaultBackend.
 	// ```
-	// svc_my_service->ing_my_ingress[ dir=back ];
+	// svc_my_service->ing_my_ingress[ dir=back, label="example.com/api\n*/" ];
 	// ```
-	for _, ing := range g.res.Ingresses.Items {
+	// Resource backends are drawn in the same way, if their kinds are available, like extra kinds.
+	ns := res.Namespace
+	for _, ing := range res.Ingresses.Items {
+		// Labels for the same backend are put on one edge
+		targets := []string{}
+		labels := map[string][]string{}
+		addBackend := func(backend *netv1.IngressBackend, label string) {
+			kind, name, ok := g.ingBackend(res, &ing, backend)
+			if !ok {
+				return
+			}
+			target := g.resourceName(ns, kind, name)
+			if _, ok := labels[target]; !ok {
+				targets = append(targets, target)
+			}
+			labels[target] = append(labels[target], label)
+		}
+
+		if ing.Spec.DefaultBackend != nil {
+			addBackend(ing.Spec.DefaultBackend, "default")
+		}
 		for _, rule := range ing.Spec.Rules {
-			for _, path := range rule.IngressRuleValue.HTTP.Paths {
-				if !g.res.HasResource("svc", path.Backend.Service.Name) {
-					fmt.Fprintf(os.Stderr, "svc %s not found for ingress %s\n", path.Backend.Service.Name, ing.Name)
+			// Rules without http, like host only ones, have no backends
+			if rule.IngressRuleValue.HTTP == nil {
+				continue
+			}
+			host := rule.Host
+			if host == "" {
+				host = "*"
+			}
+			for i := range rule.IngressRuleValue.HTTP.Paths {
+				path := &rule.IngressRuleValue.HTTP.Paths[i]
+				addBackend(&path.Backend, host+path.Path)
+			}
+		}
+
+		for _, target := range targets {
+			err := g.gviz.AddEdge(target, g.resourceName(ns, "ing", ing.Name), true,
+				map[string]string{"dir": "back", "label": "\"" + strings.Join(labels[target], "\\n") + "\""})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", target, g.resourceName(ns, "ing", ing.Name), err)
+			}
+		}
+	}
+}
+
+// ingBackend returns the kind and the name of the backend of the ingress, and whether it's found in res
+func (g *Graph) ingBackend(res *resources.Resources, ing *netv1.Ingress, backend *netv1.IngressBackend) (string, string, bool) {
+	switch {
+	case backend.Service != nil:
+		if !res.HasResource("svc", backend.Service.Name) {
+			fmt.Fprintf(os.Stderr, "svc %s not found for ingress %s\n", backend.Service.Name, ing.Name)
+			return "", "", false
+		}
+		return "svc", backend.Service.Name, true
+	case backend.Resource != nil:
+		group := ""
+		if backend.Resource.APIGroup != nil {
+			group = *backend.Resource.APIGroup
+		}
+		kind, err := res.NormalizeKind(group+"/", backend.Resource.Kind)
+		if err != nil {
+			// Skip resource that isn't available for this tool, like CRD not in Extras
+			return "", "", false
+		}
+		if !res.HasResource(kind, backend.Resource.Name) {
+			fmt.Fprintf(os.Stderr, "%s %s not found for ingress %s\n", kind, backend.Resource.Name, ing.Name)
+			return "", "", false
+		}
+		return kind, backend.Resource.Name, true
+	}
+	return "", "", false
+}
+
+// genIngTLSRef generates the edges of Ingress to the Secrets for TLS
+func (g *Graph) genIngTLSRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - networking.k8s.io/v1.Ingress.spec.tls[].secretName
+	//   - v1.Secret.metadata.name
+	// ```
+	// secret_my_secret->ing_my_ingress[ dir=none, style=dotted ];
+	// ```
+	ns := res.Namespace
+	for _, ing := range res.Ingresses.Items {
+		added := map[string]bool{}
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName == "" || added[tls.SecretName] {
+				continue
+			}
+			added[tls.SecretName] = true
+			if !res.HasResource("secret", tls.SecretName) {
+				fmt.Fprintf(os.Stderr, "secret %s not found for ingress %s\n", tls.SecretName, ing.Name)
+				continue
+			}
+
+			err := g.gviz.AddEdge(g.resourceName(ns, "secret", tls.SecretName), g.resourceName(ns, "ing", ing.Name), true, map[string]string{"dir": "none", "style": "dotted"})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "secret", tls.SecretName), g.resourceName(ns, "ing", ing.Name), err)
+			}
+		}
+	}
+}
+
+// genIngClassRef generates the edges of Ingress to IngressClass
+func (g *Graph) genIngClassRef(res *resources.Resources) {
+	// Add edge for below, which is drawn with the node for the IngressClass:
+	//   - networking.k8s.io/v1.Ingress.spec.ingressClassName, or kubernetes.io/ingress.class annotation
+	// ```
+	// ing_my_ingress->ingclass_my_ingressclass;
+	// ```
+	ns := res.Namespace
+	for _, ing := range res.Ingresses.Items {
+		class := resources.IngressClassName(&ing)
+		if class == "" {
+			continue
+		}
+
+		err := g.gviz.AddEdge(g.resourceName(ns, "ing", ing.Name), g.clusterResourceName("ingclass", class), true, map[string]string{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, "ing", ing.Name), g.clusterResourceName("ingclass", class), err)
+		}
+	}
+}
+
+// genRouteRef generates the edges of HTTPRoute and GRPCRoute to Gateway and Service references
+func (g *Graph) genRouteRef(res *resources.Resources) {
+	// Add edge if below matches:
+	//   - gateway.networking.k8s.io/v1.HTTPRoute.spec.parentRefs[]
+	//   - gateway.networking.k8s.io/v1.Gateway.metadata.name
+	// ```
+	// httproute_my_route->gtw_my_gateway;
+	// ```
+	// and below matches:
+	//   - gateway.networking.k8s.io/v1.HTTPRoute.spec.rules[].backendRefs[]
+	//   - v1.Service.metadata.name
+	// ```
+	// svc_my_service->httproute_my_route[ dir=back ];
+	// ```
+	// backendRefs to other namespaces are drawn only if ReferenceGrants allow them.
+	ns := res.Namespace
+	for _, rk := range []struct {
+		kind    string
+		apiKind string
+		routes  *unstructured.UnstructuredList
+	}{
+		{"httproute", "HTTPRoute", res.HTTPRoutes},
+		{"grpcroute", "GRPCRoute", res.GRPCRoutes},
+	} {
+		for i := range rk.routes.Items {
+			route := &rk.routes.Items[i]
+			added := map[string]bool{}
+
+			for _, ref := range resources.RouteParentRefs(route) {
+				if ref.Group != resources.GatewayGroup || ref.Kind != "Gateway" {
+					continue
+				}
+				target := g.namespaceResources(ref.Namespace)
+				if target == nil || !target.HasResource("gtw", ref.Name) {
+					fmt.Fprintf(os.Stderr, "gateway %s/%s not found for %s %s\n", ref.Namespace, ref.Name, rk.kind, route.GetName())
+					continue
+				}
+				if added["gtw/"+ref.Namespace+"/"+ref.Name] {
+					continue
+				}
+				added["gtw/"+ref.Namespace+"/"+ref.Name] = true
+
+				err := g.gviz.AddEdge(g.resourceName(ns, rk.kind, route.GetName()), g.resourceName(ref.Namespace, "gtw", ref.Name), true, map[string]string{})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ns, rk.kind, route.GetName()), g.resourceName(ref.Namespace, "gtw", ref.Name), err)
+				}
+			}
+
+			for _, ref := range resources.RouteBackendRefs(route) {
+				if ref.Group != "" || ref.Kind != "Service" {
+					continue
+				}
+				if !resources.ReferenceGranted(g.ress, resources.GatewayGroup, rk.apiKind, ns, ref) {
+					fmt.Fprintf(os.Stderr, "svc %s/%s for %s %s isn't allowed by any referencegrant\n", ref.Namespace, ref.Name, rk.kind, route.GetName())
+					continue
+				}
+				target := g.namespaceResources(ref.Namespace)
+				if target == nil || !target.HasResource("svc", ref.Name) {
+					fmt.Fprintf(os.Stderr, "svc %s/%s not found for %s %s\n", ref.Namespace, ref.Name, rk.kind, route.GetName())
+					continue
+				}
+				if added["svc/"+ref.Namespace+"/"+ref.Name] {
 					continue
 				}
+				added["svc/"+ref.Namespace+"/"+ref.Name] = true
 
-				err := g.gviz.AddEdge(g.resourceName("svc", path.Backend.Service.Name), g.resourceName("ing", ing.Name), true, map[string]string{"dir": "back"})
+				err := g.gviz.AddEdge(g.resourceName(ref.Namespace, "svc", ref.Name), g.resourceName(ns, rk.kind, route.GetName()), true, map[string]string{"dir": "back"})
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName("svc", path.Backend.Service.Name), g.resourceName("ing", ing.Name), err)
+					fmt.Fprintf(os.Stderr, "Failed to add edge from %s to %s: %v\n", g.resourceName(ref.Namespace, "svc", ref.Name), g.resourceName(ns, rk.kind, route.GetName()), err)
 				}
 			}
 		}
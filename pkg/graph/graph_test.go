@@ -12,17 +12,27 @@ import (
 	"github.com/andreyvit/diff"
 	"github.com/mkimuram/k8sviz/pkg/resources"
 	appsv1 "k8s.io/api/apps/v1"
-	autov1 "k8s.io/api/autoscaling/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
 var (
-	testns       = "testns"
+	testns  = "testns"
+	testns2 = "testns2"
+	// digitns is the namespace that starts with a digit, which is valid for namespaces
+	digitns      = "1team"
 	dir          = "/testdir"
 	goldenDir    = "testdata"
 	goldenSuffix = ".golden"
@@ -48,8 +58,8 @@ var (
 			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "deploy1"}}}},
 		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "deploy1",
 			Labels: map[string]string{"app": "rs1"}}},
-		&autov1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "hpa1"},
-			Spec: autov1.HorizontalPodAutoscalerSpec{ScaleTargetRef: autov1.CrossVersionObjectReference{Kind: "Deployment", Name: "deploy1", APIVersion: "apps/v1"}}},
+		&autov2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "hpa1"},
+			Spec: autov2.HorizontalPodAutoscalerSpec{ScaleTargetRef: autov2.CrossVersionObjectReference{Kind: "Deployment", Name: "deploy1", APIVersion: "apps/v1"}}},
 		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "ing1"},
 			Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{
 				{IngressRuleValue: netv1.IngressRuleValue{
@@ -124,6 +134,376 @@ var (
 			OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1beta1", Kind: "cronJob", Name: "cronjob1"}}}},
 		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "cronjob1"}},
 	}
+	testRes5 = []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Rollout",
+			"metadata":   map[string]interface{}{"namespace": testns, "name": "rollout1"},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "rollout1"}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1-pod1",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1"}}}},
+		&autov2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "hpa1"},
+			Spec: autov2.HorizontalPodAutoscalerSpec{ScaleTargetRef: autov2.CrossVersionObjectReference{Kind: "Rollout", Name: "rollout1", APIVersion: "argoproj.io/v1alpha1"}}},
+	}
+	testRes4 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1",
+			Labels: map[string]string{"app": "app1"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1"},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "app1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns2, Name: "pod1",
+			Labels: map[string]string{"app": "app1"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns2, Name: "svc1"},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "app1"}}},
+	}
+	testRes20 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web",
+			Labels: map[string]string{"app": "web"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: digitns, Name: "web",
+			Labels: map[string]string{"app": "web"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: digitns, Name: "web"},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+	}
+	testRes6 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1"},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{Name: "vol1", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm1"}}}},
+					{Name: "vol2", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "secret1"}}},
+				},
+				Containers: []corev1.Container{{Name: "c1",
+					Env:     []corev1.EnvVar{{Name: "ENV1", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "secret2"}, Key: "key1"}}}},
+					EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm-optional"}}}},
+				}},
+			}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod2"},
+			Spec: corev1.PodSpec{
+				InitContainers:   []corev1.Container{{Name: "c1", EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm1"}}}}}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "secret3"}},
+			}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "cm1"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "cm2"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "secret1"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "secret2"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "secret3"}},
+	}
+	testRes7 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1"},
+			Spec: corev1.PodSpec{ServiceAccountName: "sa1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod2"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "default"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "sa1"}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "role1"}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rb1"},
+			RoleRef:  rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "role1"},
+			Subjects: []rbacv1.Subject{{Kind: "ServiceAccount", Name: "sa1"}}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rb2"},
+			RoleRef: rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "view"},
+			Subjects: []rbacv1.Subject{
+				{Kind: "ServiceAccount", Name: "default", Namespace: testns},
+				{Kind: "ServiceAccount", Name: "sa2", Namespace: testns2},
+				{Kind: "User", Name: "user1"},
+			}},
+	}
+	testRes8 = []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testns2, Labels: map[string]string{"team": "a"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web",
+			Labels: map[string]string{"app": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db",
+			Labels: map[string]string{"app": "db"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns2, Name: "client",
+			Labels: map[string]string{"app": "client"}}},
+		&netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "deny-all"},
+			Spec: netv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{}}},
+		&netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db"},
+			Spec: netv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				Ingress: []netv1.NetworkPolicyIngressRule{{From: []netv1.NetworkPolicyPeer{
+					{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+				}}},
+			}},
+		&netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+			Spec: netv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Ingress: []netv1.NetworkPolicyIngressRule{{From: []netv1.NetworkPolicyPeer{
+					{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}},
+				}}},
+			}},
+	}
+	testRes9 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1",
+			Labels: map[string]string{"app": "app1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod2",
+			Labels: map[string]string{"app": "app1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod3"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1"},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "app1"}}},
+		// svc2 has no selector and its EndpointSlice is managed manually
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc2"}},
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1-ipv4",
+			Labels: map[string]string{discoveryv1.LabelServiceName: "svc1"}},
+			Endpoints: []discoveryv1.Endpoint{
+				{TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "pod1"}, Conditions: discoveryv1.EndpointConditions{Ready: &[]bool{true}[0]}},
+				{TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "pod2"}, Conditions: discoveryv1.EndpointConditions{Ready: &[]bool{false}[0]}},
+			}},
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1-ipv6",
+			Labels: map[string]string{discoveryv1.LabelServiceName: "svc1"}},
+			Endpoints: []discoveryv1.Endpoint{
+				{TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "pod1"}, Conditions: discoveryv1.EndpointConditions{Ready: &[]bool{true}[0]}},
+			}},
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc2-manual",
+			Labels: map[string]string{discoveryv1.LabelServiceName: "svc2"}},
+			Endpoints: []discoveryv1.Endpoint{
+				{TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "pod3"}},
+				{Addresses: []string{"192.0.2.1"}},
+			}},
+	}
+	testRes10 = []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "Gateway",
+			"metadata":   map[string]interface{}{"namespace": testns, "name": "gw1"},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "HTTPRoute",
+			"metadata":   map[string]interface{}{"namespace": testns, "name": "route1"},
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{map[string]interface{}{"name": "gw1", "sectionName": "http"}},
+				"rules": []interface{}{
+					map[string]interface{}{"backendRefs": []interface{}{
+						map[string]interface{}{"name": "svc1", "port": int64(80)},
+						// svc2 in testns2 is allowed by grant1, but svc3 isn't
+						map[string]interface{}{"name": "svc2", "namespace": testns2, "port": int64(80)},
+						map[string]interface{}{"name": "svc3", "namespace": testns2, "port": int64(80)},
+					}},
+				},
+			},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "GRPCRoute",
+			"metadata":   map[string]interface{}{"namespace": testns, "name": "grpcroute1"},
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "gw1"}},
+				"rules": []interface{}{
+					map[string]interface{}{"backendRefs": []interface{}{map[string]interface{}{"name": "svc1", "port": int64(9000)}}},
+					map[string]interface{}{"backendRefs": []interface{}{map[string]interface{}{"name": "svc1", "port": int64(9001)}}},
+				},
+			},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1beta1",
+			"kind":       "ReferenceGrant",
+			"metadata":   map[string]interface{}{"namespace": testns2, "name": "grant1"},
+			"spec": map[string]interface{}{
+				"from": []interface{}{map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "namespace": testns}},
+				"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service", "name": "svc2"}},
+			},
+		}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns2, Name: "svc2"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns2, Name: "svc3"}},
+	}
+	testRes11 = []runtime.Object{
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1-pod1",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1"}}},
+			Spec: corev1.PodSpec{NodeName: "node-2"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1-pod2",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1"}}},
+			Spec: corev1.PodSpec{NodeName: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1-pod3",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1"}}},
+			Spec: corev1.PodSpec{NodeName: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1-pod4",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodPending}},
+	}
+	testRes12 = []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "deploy1"}},
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "ing1"}},
+		&autov2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "hpa1"},
+			Spec: autov2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autov2.CrossVersionObjectReference{Kind: "Deployment", Name: "deploy1", APIVersion: "apps/v1"},
+				MinReplicas:    &[]int32{2}[0],
+				MaxReplicas:    10,
+				Metrics: []autov2.MetricSpec{
+					{Type: autov2.ResourceMetricSourceType, Resource: &autov2.ResourceMetricSource{Name: corev1.ResourceCPU,
+						Target: autov2.MetricTarget{Type: autov2.UtilizationMetricType, AverageUtilization: &[]int32{50}[0]}}},
+					{Type: autov2.PodsMetricSourceType, Pods: &autov2.PodsMetricSource{Metric: autov2.MetricIdentifier{Name: "packets-per-second"},
+						Target: autov2.MetricTarget{Type: autov2.AverageValueMetricType, AverageValue: resource.NewQuantity(1000, resource.DecimalSI)}}},
+					{Type: autov2.ObjectMetricSourceType, Object: &autov2.ObjectMetricSource{Metric: autov2.MetricIdentifier{Name: "requests-per-second"},
+						DescribedObject: autov2.CrossVersionObjectReference{Kind: "Ingress", Name: "ing1", APIVersion: "networking.k8s.io/v1"},
+						Target:          autov2.MetricTarget{Type: autov2.ValueMetricType, Value: resource.NewQuantity(2000, resource.DecimalSI)}}},
+					{Type: autov2.ExternalMetricSourceType, External: &autov2.ExternalMetricSource{Metric: autov2.MetricIdentifier{Name: "queue-messages-ready"},
+						Target: autov2.MetricTarget{Type: autov2.AverageValueMetricType, AverageValue: resource.NewQuantity(30, resource.DecimalSI)}}},
+				},
+			},
+			Status: autov2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 3,
+				DesiredReplicas: 4,
+				CurrentMetrics: []autov2.MetricStatus{
+					{Type: autov2.ResourceMetricSourceType, Resource: &autov2.ResourceMetricStatus{Name: corev1.ResourceCPU,
+						Current: autov2.MetricValueStatus{AverageUtilization: &[]int32{65}[0], AverageValue: resource.NewMilliQuantity(300, resource.DecimalSI)}}},
+				},
+			}},
+	}
+	testRes13 = []runtime.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc2"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "secret1"}},
+		// rollout1 is a backend of Resource kind, which is drawn as an extra kind
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Rollout",
+			"metadata":   map[string]interface{}{"namespace": testns, "name": "rollout1"},
+		}},
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "ing1"},
+			Spec: netv1.IngressSpec{
+				IngressClassName: &[]string{"nginx"}[0],
+				DefaultBackend:   &netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "svc2"}},
+				TLS:              []netv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "secret1"}},
+				Rules: []netv1.IngressRule{
+					{Host: "example.com", IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+						Paths: []netv1.HTTPIngressPath{
+							{Path: "/api", Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "svc1"}}},
+							{Path: "/static", Backend: netv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{
+								APIGroup: &[]string{"argoproj.io"}[0], Kind: "Rollout", Name: "rollout1"}}},
+						},
+					}}},
+					{IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+						Paths: []netv1.HTTPIngressPath{
+							{Path: "/", Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "svc1"}}},
+						},
+					}}},
+					// Host only rule without http
+					{Host: "www.example.com"},
+				},
+			}},
+		&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "ing2",
+			Annotations: map[string]string{"kubernetes.io/ingress.class": "nginx"}},
+			Spec: netv1.IngressSpec{DefaultBackend: &netv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{
+				APIGroup: &[]string{"example.com"}[0], Kind: "Bucket", Name: "bucket1"}}}},
+	}
+	testRes14 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod-running"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "c1", Ready: true}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod-crashloop"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "c1",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod-imagepull"},
+			Status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{{Name: "c1",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}}}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod-completed"},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod-terminating", DeletionTimestamp: &metav1.Time{}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+	}
+	testRes19 = []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "istio-init", Image: "istio/proxyv2:1.20.0"}},
+				Containers:     []corev1.Container{{Name: "nginx", Image: "nginx:1.25"}, {Name: "istio-proxy", Image: "istio/proxyv2:1.20.0"}}}}}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "proxy"},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "nginx", Image: "docker.io/library/nginx:1.24"}}}}}},
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "backup"},
+			Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "backup", Image: "registry.example.com/tools/backup:latest"}}}}}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "backup-1"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "registry.example.com/tools/backup:latest"}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "backup",
+				ImageID: "registry.example.com/tools/backup@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097"}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "backup-2"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "registry.example.com/tools/backup:latest"}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "backup",
+				ImageID: "registry.example.com/tools/backup@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"}}}},
+	}
+	testRes18 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "istio-init", Image: "istio/proxyv2:1.20.0"}},
+				Containers: []corev1.Container{
+					{Name: "app", Image: "registry.example.com/team/app:v1",
+						Ports: []corev1.ContainerPort{{ContainerPort: 8080}, {ContainerPort: 53, Protocol: corev1.ProtocolUDP}}},
+					{Name: "istio-proxy", Image: "istio/proxyv2:1.20.0"},
+				},
+				EphemeralContainers: []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", Ready: true, RestartCount: 3}, {Name: "istio-proxy", Ready: true}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod2"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}}},
+	}
+	testRes17 = []runtime.Object{
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+			Spec: appsv1.StatefulSetSpec{ServiceName: "web-headless",
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web-0",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "web"}}},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-web-0"}}}}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "data-web-0"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "data-web-1"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web-headless"},
+			Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db"},
+			Spec: appsv1.StatefulSetSpec{ServiceName: "db-headless"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "cache"},
+			Spec: appsv1.StatefulSetSpec{ServiceName: "cache"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "cache"},
+			Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.10"}},
+	}
+	testRes16 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-bound"}}}}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pvc-bound"},
+			Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pv1", StorageClassName: &[]string{"standard"}[0],
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound, Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pvc-pending"},
+			Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &[]string{"fast"}[0],
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+				Resources:   corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}}},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pvc-lost"},
+			Spec:   corev1.PersistentVolumeClaimSpec{VolumeName: "pv-deleted", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimLost}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"},
+			Spec: corev1.PersistentVolumeSpec{StorageClassName: "standard", PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+				Capacity:    corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				ClaimRef:    &corev1.ObjectReference{Namespace: testns, Name: "pvc-bound"}}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv2"},
+			Spec: corev1.PersistentVolumeSpec{StorageClassName: "standard",
+				ClaimRef: &corev1.ObjectReference{Namespace: testns2, Name: "pvc-bound"}}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Provisioner: "kubernetes.io/gce-pd"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}, Provisioner: "pd.csi.storage.gke.io",
+			VolumeBindingMode: &[]storagev1.VolumeBindingMode{storagev1.VolumeBindingWaitForFirstConsumer}[0]},
+	}
+	testRes15 = []runtime.Object{
+		&corev1.ReplicationController{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rc1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1", Labels: map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicationController", Name: "rc1"}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod2", Labels: map[string]string{"app": "db"}}},
+		&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pdb1"},
+			Spec: policyv1.PodDisruptionBudgetSpec{MinAvailable: &[]intstr.IntOrString{intstr.FromInt(1)}[0],
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			Status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 1, DisruptionsAllowed: 0}},
+		&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "quota1"},
+			Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("10"), corev1.ResourceRequestsCPU: resource.MustParse("4")}},
+			Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}}},
+		&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "limits1"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{Type: corev1.LimitTypeContainer,
+				Default: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Max:     corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}}}}},
+	}
 )
 
 func prepTestGraph(t *testing.T, objs ...runtime.Object) *Graph {
@@ -136,6 +516,35 @@ func prepTestGraph(t *testing.T, objs ...runtime.Object) *Graph {
 	return NewGraph(res, dir)
 }
 
+func prepTestGraphWithOptions(t *testing.T, opts resources.Options, graphOpts Options, objs ...runtime.Object) *Graph {
+	opts.DynamicClient = resources.NewOfflineDynamicClient(objs)
+	res, err := resources.NewResourcesWithOptions(resources.NewOfflineClientset(objs), testns, opts)
+	if err != nil {
+		t.Fatalf("NewResourcesWithOptions failed: %v", err)
+	}
+
+	return NewGraphWithOptions([]*resources.Resources{res}, dir, graphOpts)
+}
+
+func prepTestGraphForNamespaces(t *testing.T, namespaces []string, objs ...runtime.Object) *Graph {
+	return prepTestGraphForNamespacesWithOptions(t, namespaces, Options{}, objs...)
+}
+
+func prepTestGraphForNamespacesWithOptions(t *testing.T, namespaces []string, opts Options, objs ...runtime.Object) *Graph {
+	cs := resources.NewOfflineClientset(objs)
+	resOpts := resources.Options{DynamicClient: resources.NewOfflineDynamicClient(objs)}
+	ress := []*resources.Resources{}
+	for _, ns := range namespaces {
+		res, err := resources.NewResourcesWithOptions(cs, ns, resOpts)
+		if err != nil {
+			t.Fatalf("NewResourcesWithOptions failed: %v", err)
+		}
+		ress = append(ress, res)
+	}
+
+	return NewGraphWithOptions(ress, dir, opts)
+}
+
 func getGoldenFilePath(name string) string {
 	return filepath.Join(goldenDir, name+goldenSuffix)
 }
@@ -212,6 +621,16 @@ func TestGenerate(t *testing.T) {
 			res:      testRes3,
 			expected: "generate_res3",
 		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes6",
+			res:      testRes6,
+			expected: "generate_res6",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes7",
+			res:      testRes7,
+			expected: "generate_res7",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -235,3 +654,197 @@ func TestGenerate(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateFromSnapshot(t *testing.T) {
+	testCases := []struct {
+		name     string
+		snapshot string
+		expected string
+	}{
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with the snapshot of testRes1",
+			snapshot: "snapshot_res1.json",
+			expected: "generate_snapshot_res1",
+		},
+	}
+
+	for _, tc := range testCases {
+		snap, err := resources.ReadSnapshotFile(filepath.Join(goldenDir, tc.snapshot))
+		if err != nil {
+			t.Fatalf("[%s] ReadSnapshotFile failed: %v", tc.name, err)
+		}
+		g := NewGraphForNamespaces(snap.Resources, dir)
+		expected, err := expectedFromGoldenFile(tc.expected)
+		if err != nil {
+			t.Fatalf("[%s] failed to get expected from golden file %s: %v", tc.name, tc.expected, err)
+		}
+
+		dot := g.toDot()
+
+		// Update golden file if -update flag is specified for this test run
+		err = updateGoldenFile(t, tc.expected, dot)
+		if err != nil {
+			t.Fatalf("[%s] failed to update golden file %s: %v", tc.name, tc.expected, err)
+		}
+
+		if expected != dot {
+			t.Fatalf("[%s] generate doesn't return expected, diff: %v", tc.name, diff.LineDiff(expected, dot))
+		}
+	}
+}
+
+func TestGenerateNamespaces(t *testing.T) {
+	testCases := []struct {
+		name       string
+		namespaces []string
+		opts       Options
+		res        []runtime.Object
+		expected   string
+	}{
+		{
+			name:       "Generate whole graph for ns=testns,testns2 and dir=/testdir with testRes4",
+			namespaces: []string{testns, testns2},
+			res:        testRes4,
+			expected:   "generate_namespaces_res4",
+		},
+		{
+			name:       "Generate whole graph for ns=testns,testns2 and dir=/testdir with testRes8 and traffic",
+			namespaces: []string{testns, testns2},
+			opts:       Options{Traffic: true},
+			res:        testRes8,
+			expected:   "generate_namespaces_traffic_res8",
+		},
+		{
+			name:       "Generate whole graph for ns=testns,testns2 and dir=/testdir with testRes10",
+			namespaces: []string{testns, testns2},
+			res:        testRes10,
+			expected:   "generate_namespaces_gateway_res10",
+		},
+		{
+			name:       "Generate whole graph for ns=testns,1team and dir=/testdir with testRes20",
+			namespaces: []string{testns, digitns},
+			res:        testRes20,
+			expected:   "generate_namespaces_digit_res20",
+		},
+	}
+
+	for _, tc := range testCases {
+		g := prepTestGraphForNamespacesWithOptions(t, tc.namespaces, tc.opts, tc.res...)
+		expected, err := expectedFromGoldenFile(tc.expected)
+		if err != nil {
+			t.Fatalf("[%s] failed to get expected from golden file %s: %v", tc.name, tc.expected, err)
+		}
+
+		dot := g.toDot()
+
+		// Update golden file if -update flag is specified for this test run
+		err = updateGoldenFile(t, tc.expected, dot)
+		if err != nil {
+			t.Fatalf("[%s] failed to update golden file %s: %v", tc.name, tc.expected, err)
+		}
+
+		if expected != dot {
+			t.Fatalf("[%s] generate doesn't return expected, diff: %v", tc.name, diff.LineDiff(expected, dot))
+		}
+	}
+}
+
+func TestGenerateWithOptions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      resources.Options
+		graphOpts Options
+		res       []runtime.Object
+		expected  string
+	}{
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes5 and extra kinds",
+			opts:     resources.Options{ExtraKinds: []string{"rollouts.argoproj.io"}},
+			res:      testRes5,
+			expected: "generate_extras_res5",
+		},
+		{
+			name:      "Generate whole graph for ns=testns and dir=/testdir with testRes9 and endpointslices",
+			opts:      resources.Options{EndpointSlices: true},
+			graphOpts: Options{EndpointSlices: true},
+			res:       testRes9,
+			expected:  "generate_endpointslices_res9",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes9 and selectors",
+			res:      testRes9,
+			expected: "generate_selectors_res9",
+		},
+		{
+			name:      "Generate whole graph for ns=testns and dir=/testdir with testRes11 and group by node",
+			graphOpts: Options{GroupByNode: true},
+			res:       testRes11,
+			expected:  "generate_group_by_node_res11",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes12",
+			res:      testRes12,
+			expected: "generate_hpa_res12",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes13 and ingress backends",
+			opts:     resources.Options{ExtraKinds: []string{"rollouts.argoproj.io"}},
+			res:      testRes13,
+			expected: "generate_ingress_res13",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes14 and pod statuses",
+			res:      testRes14,
+			expected: "generate_pod_status_res14",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes15 and replication controller, pdb, quota and limit range",
+			res:      testRes15,
+			expected: "generate_policy_res15",
+		},
+		{
+			name:      "Generate whole graph for ns=testns and dir=/testdir with testRes16 and storage",
+			res:       testRes16,
+			opts:      resources.Options{Storage: true},
+			graphOpts: Options{Storage: true},
+			expected:  "generate_storage_res16",
+		},
+		{
+			name:     "Generate whole graph for ns=testns and dir=/testdir with testRes17 and statefulsets",
+			res:      testRes17,
+			expected: "generate_sts_res17",
+		},
+		{
+			name:      "Generate whole graph for ns=testns and dir=/testdir with testRes18 and containers",
+			res:       testRes18,
+			graphOpts: Options{Containers: true},
+			expected:  "generate_containers_res18",
+		},
+		{
+			name:      "Generate whole graph for ns=testns and dir=/testdir with testRes19 and images",
+			res:       testRes19,
+			graphOpts: Options{Images: true},
+			expected:  "generate_images_res19",
+		},
+	}
+
+	for _, tc := range testCases {
+		g := prepTestGraphWithOptions(t, tc.opts, tc.graphOpts, tc.res...)
+		expected, err := expectedFromGoldenFile(tc.expected)
+		if err != nil {
+			t.Fatalf("[%s] failed to get expected from golden file %s: %v", tc.name, tc.expected, err)
+		}
+
+		dot := g.toDot()
+
+		// Update golden file if -update flag is specified for this test run
+		err = updateGoldenFile(t, tc.expected, dot)
+		if err != nil {
+			t.Fatalf("[%s] failed to update golden file %s: %v", tc.name, tc.expected, err)
+		}
+
+		if expected != dot {
+			t.Fatalf("[%s] generate doesn't return expected, diff: %v", tc.name, diff.LineDiff(expected, dot))
+		}
+	}
+}
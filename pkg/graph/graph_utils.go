@@ -5,22 +5,108 @@ package graph
 
 import (
 	"fmt"
+	"html"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/mkimuram/k8sviz/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // imagePath returns the path to the image file
-// path is {dir}/icons/{resource}-128.png
+// path is {dir}/icons/{icon}-128.png, where icon is the one of the registered kind or the kind itself
 // ex) /icons/pod-128.png
 func (g *Graph) imagePath(kind string) string {
-	return filepath.Join(g.dir, "icons", kind+imageSuffix)
+	return filepath.Join(g.dir, "icons", resources.KindIcon(kind)+imageSuffix)
 }
 
 // clusterLabel returns the resource label for namespace
+// Policies, like resource quotas, are the rows shown below the namespace, which are returned by policyRows.
+// Kinds that were not visible, like forbidden ones, are noted below them.
 // ex)
 //   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/ns-128.png" /></TD></TR><TR><TD>my-namespace</TD></TR></TABLE>>
-func (g *Graph) clusterLabel() string {
-	return g.resourceLabel("ns", g.res.Namespace)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/ns-128.png" /></TD></TR><TR><TD>my-namespace</TD></TR><TR><TD><FONT COLOR="red">not visible: hpa, ing</FONT></TD></TR></TABLE>>
+func (g *Graph) clusterLabel(ns string, invisible []string, policies ...string) string {
+	if len(invisible) == 0 && len(policies) == 0 {
+		return g.resourceLabel("ns", ns)
+	}
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("ns"), ns)
+	label += strings.Join(policies, "")
+	if len(invisible) > 0 {
+		label += fmt.Sprintf("<TR><TD><FONT COLOR=\"red\">not visible: %s</FONT></TD></TR>", strings.Join(invisible, ", "))
+	}
+	return label + "</TABLE>>"
+}
+
+// policyRows returns the rows for the resource quotas and the limit ranges in res, each with a small icon
+// Quotas show the used amount of each resource, if it's reported, and the hard limit.
+// ex)
+//   <TR><TD ALIGN="LEFT"><TABLE BORDER="0"><TR><TD FIXEDSIZE="TRUE" WIDTH="24" HEIGHT="24"><IMG SRC="/icons/quota-128.png" SCALE="TRUE" /></TD><TD>my-quota: cpu 500m/4, pods 3/10</TD></TR></TABLE></TD></TR>
+//   <TR><TD ALIGN="LEFT"><TABLE BORDER="0"><TR><TD FIXEDSIZE="TRUE" WIDTH="24" HEIGHT="24"><IMG SRC="/icons/limits-128.png" SCALE="TRUE" /></TD><TD>my-limits: Container max memory=1Gi, default cpu=500m</TD></TR></TABLE></TD></TR>
+func (g *Graph) policyRows(res *resources.Resources) []string {
+	rows := []string{}
+	for _, quota := range res.ResourceQuotas.Items {
+		usages := []string{}
+		for _, name := range resourceNames(quota.Spec.Hard) {
+			hard := quota.Spec.Hard[name]
+			if used, ok := quota.Status.Used[name]; ok {
+				usages = append(usages, fmt.Sprintf("%s %s/%s", name, used.String(), hard.String()))
+			} else {
+				usages = append(usages, fmt.Sprintf("%s %s", name, hard.String()))
+			}
+		}
+		rows = append(rows, g.policyRow("quota", fmt.Sprintf("%s: %s", quota.Name, strings.Join(usages, ", "))))
+	}
+	for _, lr := range res.LimitRanges.Items {
+		items := []string{}
+		for _, item := range lr.Spec.Limits {
+			limits := []string{}
+			for _, l := range []struct {
+				name string
+				list corev1.ResourceList
+			}{
+				{"min", item.Min},
+				{"max", item.Max},
+				{"default", item.Default},
+				{"defaultRequest", item.DefaultRequest},
+				{"maxLimitRequestRatio", item.MaxLimitRequestRatio},
+			} {
+				if len(l.list) == 0 {
+					continue
+				}
+				values := []string{}
+				for _, name := range resourceNames(l.list) {
+					value := l.list[name]
+					values = append(values, fmt.Sprintf("%s=%s", name, value.String()))
+				}
+				limits = append(limits, fmt.Sprintf("%s %s", l.name, strings.Join(values, " ")))
+			}
+			items = append(items, fmt.Sprintf("%s %s", item.Type, strings.Join(limits, ", ")))
+		}
+		rows = append(rows, g.policyRow("limits", fmt.Sprintf("%s: %s", lr.Name, strings.Join(items, "; "))))
+	}
+	return rows
+}
+
+// policyRow returns the row with the small icon for the kind and the text
+func (g *Graph) policyRow(kind, text string) string {
+	return fmt.Sprintf("<TR><TD ALIGN=\"LEFT\"><TABLE BORDER=\"0\"><TR><TD FIXEDSIZE=\"TRUE\" WIDTH=\"24\" HEIGHT=\"24\"><IMG SRC=\"%s\" SCALE=\"TRUE\" /></TD><TD>%s</TD></TR></TABLE></TD></TR>", g.imagePath(kind), html.EscapeString(text))
+}
+
+// resourceNames returns the names of the resources in list in sorted order
+func resourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := []corev1.ResourceName{}
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
 }
 
 // resourceLabel returns the resource label for a resource
@@ -30,11 +116,460 @@ func (g *Graph) resourceLabel(kind, name string) string {
 	return fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR></TABLE>>", g.imagePath(kind), name)
 }
 
+// nodeAttrs returns the attributes for the node of the resource in res
+// Pods with problems, like CrashLoopBackOff, lost pvcs in the storage view and statefulsets without
+// their governing services are outlined in red to be noticed.
+func (g *Graph) nodeAttrs(res *resources.Resources, kind, name string) map[string]string {
+	attrs := map[string]string{"label": g.nodeLabel(res, kind, name), "penwidth": "0"}
+	problem := false
+	switch kind {
+	case "pod":
+		pod := podByName(res, name)
+		problem = pod != nil && resources.GetPodStatus(pod).IsProblem()
+	case "pvc":
+		if g.opts.Storage {
+			pvc := pvcByName(res, name)
+			problem = pvc != nil && pvc.Status.Phase == corev1.ClaimLost
+		}
+	case "sts":
+		if sts := stsByName(res, name); sts != nil {
+			_, problem = stsServiceNote(res, sts)
+		}
+	}
+	if problem {
+		attrs["color"] = problemColor
+		attrs["penwidth"] = "2"
+		attrs["shape"] = "box"
+		attrs["style"] = "rounded"
+	}
+	return attrs
+}
+
+// nodeLabel returns the label for the node of the resource in res
+// Some kinds show their details below the name, like the scaling policy of hpa and the status of pod.
+func (g *Graph) nodeLabel(res *resources.Resources, kind, name string) string {
+	switch kind {
+	case "hpa":
+		for i := range res.Hpas.Items {
+			if res.Hpas.Items[i].Name == name {
+				return g.hpaLabel(&res.Hpas.Items[i])
+			}
+		}
+	case "pod":
+		if pod := podByName(res, name); pod != nil {
+			return g.podLabel(pod)
+		}
+	case "pvc":
+		if pvc := pvcByName(res, name); pvc != nil && g.opts.Storage {
+			return g.pvcLabel(pvc)
+		}
+	case "sts":
+		if sts := stsByName(res, name); sts != nil {
+			return g.stsLabel(res, sts)
+		}
+	case "pdb":
+		for i := range res.Pdbs.Items {
+			if res.Pdbs.Items[i].Name == name {
+				return g.pdbLabel(&res.Pdbs.Items[i])
+			}
+		}
+	}
+	return g.resourceLabel(kind, name)
+}
+
+// podLabel returns the resource label for a pod, with its status in the color for the status
+// Pods whose status isn't reported yet are drawn without status.
+// With Options.Containers, the containers in the pod are listed below them.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pod-128.png" /></TD></TR><TR><TD>my-pod</TD></TR><TR><TD><FONT COLOR="red">CrashLoopBackOff</FONT></TD></TR></TABLE>>
+func (g *Graph) podLabel(pod *corev1.Pod) string {
+	status := resources.GetPodStatus(pod)
+	if status == resources.PodStatusUnknown && !g.opts.Containers {
+		return g.resourceLabel("pod", pod.Name)
+	}
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("pod"), pod.Name)
+	if status != resources.PodStatusUnknown {
+		label += fmt.Sprintf("<TR><TD><FONT COLOR=\"%s\">%s</FONT></TD></TR>", podStatusColors[status], status)
+	}
+	if g.opts.Containers {
+		label += fmt.Sprintf("<TR><TD>%s</TD></TR>", containersTable(pod))
+	}
+	return label + "</TABLE>>"
+}
+
+// containersTable returns the table of the containers in the pod, with their images, ports and restart counts
+// Init and ephemeral containers are marked with their kinds, and restarted containers are in the warning color.
+// ex)
+//   <TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0"><TR><TD></TD><TD>container</TD><TD>image</TD><TD>ports</TD><TD>restarts</TD></TR><TR><TD>init</TD><TD>istio-init</TD><TD>proxyv2:1.20.0</TD><TD></TD><TD>0</TD></TR><TR><TD></TD><TD>app</TD><TD>app:v1</TD><TD>8080,53/UDP</TD><TD><FONT COLOR="orange">3</FONT></TD></TR></TABLE>
+func containersTable(pod *corev1.Pod) string {
+	table := "<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\"><TR><TD></TD><TD>container</TD><TD>image</TD><TD>ports</TD><TD>restarts</TD></TR>"
+	for _, c := range resources.PodContainers(pod) {
+		ports := []string{}
+		for _, port := range c.Ports {
+			if port.Protocol == "" || port.Protocol == corev1.ProtocolTCP {
+				ports = append(ports, fmt.Sprintf("%d", port.ContainerPort))
+			} else {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol))
+			}
+		}
+		restarts := fmt.Sprintf("%d", c.Restarts)
+		if c.Restarts > 0 {
+			restarts = fmt.Sprintf("<FONT COLOR=\"%s\">%d</FONT>", warningColor, c.Restarts)
+		}
+		table += fmt.Sprintf("<TR><TD>%s</TD><TD>%s</TD><TD>%s</TD><TD>%s</TD><TD>%s</TD></TR>", c.Kind, html.EscapeString(c.Name),
+			html.EscapeString(resources.ParseImage(c.Image).Short()), strings.Join(ports, ","), restarts)
+	}
+	return table + "</TABLE>"
+}
+
+// pdbLabel returns the resource label for a pod disruption budget, with its budget
+// Allowed disruptions are shown only if the status is reported.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pdb-128.png" /></TD></TR><TR><TD>my-pdb</TD></TR><TR><TD>min available: 2</TD></TR><TR><TD>disruptions allowed: 1</TD></TR></TABLE>>
+func (g *Graph) pdbLabel(pdb *policyv1.PodDisruptionBudget) string {
+	rows := []string{}
+	if pdb.Spec.MinAvailable != nil {
+		rows = append(rows, fmt.Sprintf("min available: %s", pdb.Spec.MinAvailable.String()))
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		rows = append(rows, fmt.Sprintf("max unavailable: %s", pdb.Spec.MaxUnavailable.String()))
+	}
+	if pdb.Status.ExpectedPods > 0 {
+		rows = append(rows, fmt.Sprintf("disruptions allowed: %d", pdb.Status.DisruptionsAllowed))
+	}
+
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("pdb"), pdb.Name)
+	for _, row := range rows {
+		label += fmt.Sprintf("<TR><TD>%s</TD></TR>", html.EscapeString(row))
+	}
+	return label + "</TABLE>>"
+}
+
+// pvcLabel returns the resource label for a pvc in the storage view, with its capacity, access modes and phase
+// Capacity is the requested one until the pvc is bound.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pvc-128.png" /></TD></TR><TR><TD>my-pvc</TD></TR><TR><TD>10Gi RWO</TD></TR><TR><TD><FONT COLOR="darkgreen">Bound</FONT></TD></TR></TABLE>>
+func (g *Graph) pvcLabel(pvc *corev1.PersistentVolumeClaim) string {
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok {
+		capacity = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("pvc"), pvc.Name)
+	if summary := storageSummary(capacity, pvc.Spec.AccessModes); summary != "" {
+		label += fmt.Sprintf("<TR><TD>%s</TD></TR>", html.EscapeString(summary))
+	}
+	if pvc.Status.Phase != "" {
+		label += fmt.Sprintf("<TR><TD><FONT COLOR=\"%s\">%s</FONT></TD></TR>", pvcPhaseColors[pvc.Status.Phase], pvc.Status.Phase)
+	}
+	return label + "</TABLE>>"
+}
+
+// pvLabel returns the resource label for a persistent volume, with its capacity, access modes and reclaim policy
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/pv-128.png" /></TD></TR><TR><TD>my-pv</TD></TR><TR><TD>10Gi RWO</TD></TR><TR><TD>reclaim: Delete</TD></TR></TABLE>>
+func (g *Graph) pvLabel(pv *corev1.PersistentVolume) string {
+	rows := []string{}
+	if summary := storageSummary(pv.Spec.Capacity[corev1.ResourceStorage], pv.Spec.AccessModes); summary != "" {
+		rows = append(rows, summary)
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy != "" {
+		rows = append(rows, fmt.Sprintf("reclaim: %s", pv.Spec.PersistentVolumeReclaimPolicy))
+	}
+
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("pv"), pv.Name)
+	for _, row := range rows {
+		label += fmt.Sprintf("<TR><TD>%s</TD></TR>", html.EscapeString(row))
+	}
+	return label + "</TABLE>>"
+}
+
+// scLabel returns the resource label for a storage class, with its provisioner and volume binding mode
+// The binding mode is shown only if it's WaitForFirstConsumer, which explains pending pvcs.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/sc-128.png" /></TD></TR><TR><TD>my-storageclass</TD></TR><TR><TD>kubernetes.io/gce-pd</TD></TR></TABLE>>
+func (g *Graph) scLabel(sc *storagev1.StorageClass) string {
+	rows := []string{sc.Provisioner}
+	if sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+		rows = append(rows, fmt.Sprintf("binding: %s", *sc.VolumeBindingMode))
+	}
+
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("sc"), sc.Name)
+	for _, row := range rows {
+		label += fmt.Sprintf("<TR><TD>%s</TD></TR>", html.EscapeString(row))
+	}
+	return label + "</TABLE>>"
+}
+
+// storageSummary returns the capacity and the short names of the access modes, like 10Gi RWO
+// Capacity is omitted if it isn't known.
+func storageSummary(capacity resource.Quantity, modes []corev1.PersistentVolumeAccessMode) string {
+	summary := []string{}
+	if !capacity.IsZero() {
+		summary = append(summary, capacity.String())
+	}
+	for _, mode := range modes {
+		if short, ok := accessModeNames[mode]; ok {
+			summary = append(summary, short)
+		} else {
+			summary = append(summary, string(mode))
+		}
+	}
+	return strings.Join(summary, " ")
+}
+
+// stsLabel returns the resource label for a statefulset, with the note on its governing service if any
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/sts-128.png" /></TD></TR><TR><TD>my-sts</TD></TR><TR><TD><FONT COLOR="red">service my-svc not found</FONT></TD></TR></TABLE>>
+func (g *Graph) stsLabel(res *resources.Resources, sts *appsv1.StatefulSet) string {
+	note, problem := stsServiceNote(res, sts)
+	if note == "" {
+		return g.resourceLabel("sts", sts.Name)
+	}
+	color := warningColor
+	if problem {
+		color = problemColor
+	}
+	return fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR><TR><TD><FONT COLOR=\"%s\">%s</FONT></TD></TR></TABLE>>", g.imagePath("sts"), sts.Name, color, html.EscapeString(note))
+}
+
+// stsServiceNote returns the note on the governing service of the statefulset, and whether it's a problem
+// Missing service is a problem, because the pods don't get their DNS names, and the service that isn't headless is noted.
+// It returns "" if the service is fine, or services aren't visible.
+func stsServiceNote(res *resources.Resources, sts *appsv1.StatefulSet) (string, bool) {
+	if sts.Spec.ServiceName == "" {
+		return "", false
+	}
+	for _, kind := range res.Invisible {
+		if kind == "svc" {
+			return "", false
+		}
+	}
+	for _, svc := range res.Svcs.Items {
+		if svc.Name != sts.Spec.ServiceName {
+			continue
+		}
+		if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+			return fmt.Sprintf("service %s is not headless", svc.Name), false
+		}
+		return "", false
+	}
+	return fmt.Sprintf("service %s not found", sts.Spec.ServiceName), true
+}
+
+// stsByName returns the statefulset with the name in res, or nil if it isn't found
+func stsByName(res *resources.Resources, name string) *appsv1.StatefulSet {
+	for i := range res.Stss.Items {
+		if res.Stss.Items[i].Name == name {
+			return &res.Stss.Items[i]
+		}
+	}
+	return nil
+}
+
+// imageLabel returns the resource label for an image, with the digests that the pods are running
+// Other versions of the same repository and different digests for the same tag are noted in the warning color.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/image-128.png" /></TD></TR><TR><TD>library/nginx:1.25</TD></TR><TR><TD>sha256:0123456789ab</TD></TR><TR><TD><FONT COLOR="orange">other versions: 1.24</FONT></TD></TR></TABLE>>
+func (g *Graph) imageLabel(usage *resources.ImageUsage) string {
+	name := usage.Image.Repository + ":" + usage.Image.Version()
+	if usage.Image.Tag == "" {
+		name = usage.Image.Repository + "@" + usage.Image.Version()
+	}
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("image"), html.EscapeString(name))
+	for _, digest := range usage.Digests {
+		digest = resources.ShortDigest(digest)
+		if len(usage.Digests) > 1 {
+			label += fmt.Sprintf("<TR><TD><FONT COLOR=\"%s\">%s</FONT></TD></TR>", warningColor, html.EscapeString(digest))
+		} else {
+			label += fmt.Sprintf("<TR><TD>%s</TD></TR>", html.EscapeString(digest))
+		}
+	}
+	if len(usage.OtherVersions) > 0 {
+		label += fmt.Sprintf("<TR><TD><FONT COLOR=\"%s\">other versions: %s</FONT></TD></TR>", warningColor, html.EscapeString(strings.Join(usage.OtherVersions, ", ")))
+	}
+	return label + "</TABLE>>"
+}
+
+// pvcByName returns the pvc with the name in res, or nil if it isn't found
+func pvcByName(res *resources.Resources, name string) *corev1.PersistentVolumeClaim {
+	for i := range res.Pvcs.Items {
+		if res.Pvcs.Items[i].Name == name {
+			return &res.Pvcs.Items[i]
+		}
+	}
+	return nil
+}
+
+// podByName returns the pod with the name in res, or nil if it isn't found
+func podByName(res *resources.Resources, name string) *corev1.Pod {
+	for i := range res.Pods.Items {
+		if res.Pods.Items[i].Name == name {
+			return &res.Pods.Items[i]
+		}
+	}
+	return nil
+}
+
+// hpaLabel returns the resource label for an hpa, with its replicas and metrics
+// Metrics are shown with their current values, if they are known.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/hpa-128.png" /></TD></TR><TR><TD>my-hpa</TD></TR><TR><TD>min/max: 1/10</TD></TR><TR><TD>current/desired: 2/3</TD></TR><TR><TD>resource cpu: 45% (target 50%)</TD></TR></TABLE>>
+func (g *Graph) hpaLabel(hpa *autov2.HorizontalPodAutoscaler) string {
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	rows := []string{
+		fmt.Sprintf("min/max: %d/%d", minReplicas, hpa.Spec.MaxReplicas),
+		fmt.Sprintf("current/desired: %d/%d", hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas),
+	}
+	for i := range hpa.Spec.Metrics {
+		metric := &hpa.Spec.Metrics[i]
+		target := metricTarget(metric)
+		if cur := resources.CurrentMetric(hpa, metric); cur != nil {
+			rows = append(rows, fmt.Sprintf("%s: %s (target %s)", metricName(metric), metricValue(cur), target))
+		} else {
+			rows = append(rows, fmt.Sprintf("%s: target %s", metricName(metric), target))
+		}
+	}
+
+	label := fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR>", g.imagePath("hpa"), hpa.Name)
+	for _, row := range rows {
+		label += fmt.Sprintf("<TR><TD>%s</TD></TR>", html.EscapeString(row))
+	}
+	return label + "</TABLE>>"
+}
+
+// metricName returns the type and the name of the metric for hpa
+// ex) resource cpu, object requests-per-second on Ingress/my-ingress
+func metricName(metric *autov2.MetricSpec) string {
+	switch {
+	case metric.Resource != nil:
+		return fmt.Sprintf("resource %s", metric.Resource.Name)
+	case metric.ContainerResource != nil:
+		return fmt.Sprintf("resource %s of container %s", metric.ContainerResource.Name, metric.ContainerResource.Container)
+	case metric.Pods != nil:
+		return fmt.Sprintf("pods %s", metric.Pods.Metric.Name)
+	case metric.Object != nil:
+		return fmt.Sprintf("object %s on %s/%s", metric.Object.Metric.Name, metric.Object.DescribedObject.Kind, metric.Object.DescribedObject.Name)
+	case metric.External != nil:
+		return fmt.Sprintf("external %s", metric.External.Metric.Name)
+	}
+	return strings.ToLower(string(metric.Type))
+}
+
+// metricTarget returns the target of the metric for hpa
+// ex) 50%, 500m, average 1k
+func metricTarget(metric *autov2.MetricSpec) string {
+	var target autov2.MetricTarget
+	switch {
+	case metric.Resource != nil:
+		target = metric.Resource.Target
+	case metric.ContainerResource != nil:
+		target = metric.ContainerResource.Target
+	case metric.Pods != nil:
+		target = metric.Pods.Target
+	case metric.Object != nil:
+		target = metric.Object.Target
+	case metric.External != nil:
+		target = metric.External.Target
+	}
+
+	switch {
+	case target.Type == autov2.UtilizationMetricType && target.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *target.AverageUtilization)
+	case target.Type == autov2.AverageValueMetricType && target.AverageValue != nil:
+		return "average " + target.AverageValue.String()
+	case target.Type == autov2.ValueMetricType && target.Value != nil:
+		return target.Value.String()
+	}
+	return "unknown"
+}
+
+// metricValue returns the current value of the metric for hpa
+// ex) 45%, 300m, average 800
+func metricValue(cur *autov2.MetricValueStatus) string {
+	switch {
+	case cur.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *cur.AverageUtilization)
+	case cur.AverageValue != nil:
+		return "average " + cur.AverageValue.String()
+	case cur.Value != nil:
+		return cur.Value.String()
+	}
+	return "unknown"
+}
+
+// extraResourceLabel returns the resource label for an extra resource, like CRD
+// The kind is also shown, because the icon for extra resources is common to all kinds.
+// ex)
+//   <<TABLE BORDER="0"><TR><TD><IMG SRC="/icons/crd-128.png" /></TD></TR><TR><TD>Rollout</TD></TR><TR><TD>my-rollout</TD></TR></TABLE>>
+func (g *Graph) extraResourceLabel(kind, name string) string {
+	return fmt.Sprintf("<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"%s\" /></TD></TR><TR><TD>%s</TD></TR><TR><TD>%s</TD></TR></TABLE>>", g.imagePath(extraIcon), kind, name)
+}
+
 // clusterName returns name of the graphviz cluster
 // It is named base on namespace.
 // ex) cluster_my_namespace
-func (g *Graph) clusterName() string {
-	return clusterPrefix + g.escapeName(g.res.Namespace)
+func (g *Graph) clusterName(ns string) string {
+	return clusterPrefix + g.escapeName(ns)
+}
+
+// registryClusterName returns name of the graphviz cluster for the registry of images
+// ex) cluster_registry_docker_io
+func (g *Graph) registryClusterName(registry string) string {
+	return clusterPrefix + "registry_" + g.escapeName(escapeImageRef(registry))
+}
+
+// imageName returns the name of the graphviz node for the image, which is shared across namespaces
+// ex) image_docker_io_library_nginx_1_25
+func (g *Graph) imageName(img resources.Image) string {
+	return g.clusterResourceName("image", escapeImageRef(img.String()))
+}
+
+// escapeImageRef replaces the characters in image references that can't be used in graphviz names
+func escapeImageRef(ref string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(ref)
+}
+
+// nodeClusterName returns name of the graphviz cluster for the k8s node in the namespace
+// Empty node is for the pods that aren't scheduled.
+// ex) cluster_node_my_node, cluster_unscheduled, or cluster_ns_my_namespace_node_my_node for multiple namespaces
+func (g *Graph) nodeClusterName(ns, node string) string {
+	if node == "" {
+		return clusterPrefix + g.namespacePrefix(ns) + unscheduledName
+	}
+	return clusterPrefix + g.resourceName(ns, "node", node)
+}
+
+// namespacePrefix returns the prefix to make names unique across namespaces
+// It is empty if the graph has only one namespace, so that names are kept
+// the same to the ones for a single namespace. It starts with a letter,
+// because namespaces can start with digits, which graphviz reads as numbers.
+// ex) ns_my_namespace_
+func (g *Graph) namespacePrefix(ns string) string {
+	if len(g.ress) <= 1 {
+		return ""
+	}
+	return "ns_" + g.escapeName(ns) + "_"
+}
+
+// clusterResourceName returns the name of the graphviz node for a cluster-scoped resource
+// It isn't prefixed with namespace, so that the node is shared across namespaces.
+// ex) c_role_my_clusterrole
+func (g *Graph) clusterResourceName(resType, name string) string {
+	return g.escapeName(resType) + "_" + g.escapeName(name)
+}
+
+// namespaceResources returns the resources for the namespace in the graph
+// It returns nil if the namespace isn't in the graph.
+func (g *Graph) namespaceResources(ns string) *resources.Resources {
+	for _, res := range g.ress {
+		if res.Namespace == ns {
+			return res
+		}
+	}
+	return nil
 }
 
 // escapeName returns the escaped name to be handled with graphviz
@@ -46,19 +581,19 @@ func (g *Graph) escapeName(name string) string {
 
 // resourceName returns the escaped name of the resource
 // It espaces the resource name and add resType as a prefix.
-// ex) pod_my_pod
-func (g *Graph) resourceName(resType, name string) string {
-	return resType + "_" + g.escapeName(name)
+// ex) pod_my_pod, or ns_my_namespace_pod_my_pod for multiple namespaces
+func (g *Graph) resourceName(ns, resType, name string) string {
+	return g.namespacePrefix(ns) + g.escapeName(resType) + "_" + g.escapeName(name)
 }
 
 // rankName returns the name of the dummy rank
-// ex) rank_1
-func (g *Graph) rankName(rank int) string {
-	return fmt.Sprintf("%s%d", rankPrefix, rank)
+// ex) rank_1, or ns_my_namespace_rank_1 for multiple namespaces
+func (g *Graph) rankName(ns string, rank int) string {
+	return fmt.Sprintf("%s%s%d", g.namespacePrefix(ns), rankPrefix, rank)
 }
 
 // rankDummyNodeName returns the node name of the dummy rank
-// ex) 1
-func (g *Graph) rankDummyNodeName(rank int) string {
-	return fmt.Sprintf("%d", rank)
+// ex) 1, or ns_my_namespace_1 for multiple namespaces
+func (g *Graph) rankDummyNodeName(ns string, rank int) string {
+	return fmt.Sprintf("%s%d", g.namespacePrefix(ns), rank)
 }
@@ -36,18 +36,31 @@ func TestImagePath(t *testing.T) {
 
 func TestClusterLabel(t *testing.T) {
 	testCases := []struct {
-		name     string
-		expected string
+		name      string
+		invisible []string
+		policies  []string
+		expected  string
 	}{
 		{
 			name:     "For namespace=testns and dir=/testdir",
 			expected: "<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"/testdir/icons/ns-128.png\" /></TD></TR><TR><TD>testns</TD></TR></TABLE>>",
 		},
+		{
+			name:      "For namespace=testns and dir=/testdir with invisible hpa and ing",
+			invisible: []string{"hpa", "ing"},
+			expected:  "<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"/testdir/icons/ns-128.png\" /></TD></TR><TR><TD>testns</TD></TR><TR><TD><FONT COLOR=\"red\">not visible: hpa, ing</FONT></TD></TR></TABLE>>",
+		},
+		{
+			name:      "For namespace=testns and dir=/testdir with policies and invisible hpa",
+			invisible: []string{"hpa"},
+			policies:  []string{"<TR><TD>quota</TD></TR>"},
+			expected:  "<<TABLE BORDER=\"0\"><TR><TD><IMG SRC=\"/testdir/icons/ns-128.png\" /></TD></TR><TR><TD>testns</TD></TR><TR><TD>quota</TD></TR><TR><TD><FONT COLOR=\"red\">not visible: hpa</FONT></TD></TR></TABLE>>",
+		},
 	}
 
 	g := prepTestGraph(t)
 	for _, tc := range testCases {
-		label := g.clusterLabel()
+		label := g.clusterLabel(testns, tc.invisible, tc.policies...)
 		if tc.expected != label {
 			t.Fatalf("[%s] clusterLabel doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, label)
 		}
@@ -144,9 +157,114 @@ func TestResourceName(t *testing.T) {
 
 	g := prepTestGraph(t)
 	for _, tc := range testCases {
-		name := g.resourceName(tc.kind, tc.resName)
+		name := g.resourceName(testns, tc.kind, tc.resName)
 		if tc.expected != name {
 			t.Fatalf("[%s] resourceName doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, name)
 		}
 	}
 }
+
+func TestResourceNameForNamespaces(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ns       string
+		kind     string
+		resName  string
+		expected string
+	}{
+		{
+			name:     "ns=testns, kind=pod and name=pod1 is specified",
+			ns:       "testns",
+			kind:     "pod",
+			resName:  "pod1",
+			expected: "ns_testns_pod_pod1",
+		},
+		{
+			name:     "ns=testns2, kind=svc and name=svc1 is specified",
+			ns:       "testns2",
+			kind:     "svc",
+			resName:  "svc1",
+			expected: "ns_testns2_svc_svc1",
+		},
+	}
+
+	g := prepTestGraphForNamespaces(t, []string{"testns", "testns2"})
+	for _, tc := range testCases {
+		name := g.resourceName(tc.ns, tc.kind, tc.resName)
+		if tc.expected != name {
+			t.Fatalf("[%s] resourceName doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, name)
+		}
+	}
+}
+
+func TestClusterResourceName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		kind     string
+		resName  string
+		expected string
+	}{
+		{
+			name:     "kind=c-role and name=view is specified for namespaces testns and testns2",
+			kind:     "c-role",
+			resName:  "view",
+			expected: "c_role_view",
+		},
+		{
+			name:     "kind=c-role and name=system.controller-1 is specified for namespaces testns and testns2",
+			kind:     "c-role",
+			resName:  "system.controller-1",
+			expected: "c_role_system_controller_1",
+		},
+	}
+
+	g := prepTestGraphForNamespaces(t, []string{testns, testns2})
+	for _, tc := range testCases {
+		name := g.clusterResourceName(tc.kind, tc.resName)
+		if tc.expected != name {
+			t.Fatalf("[%s] clusterResourceName doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, name)
+		}
+	}
+}
+
+func TestNodeClusterName(t *testing.T) {
+	testCases := []struct {
+		name       string
+		namespaces []string
+		node       string
+		expected   string
+	}{
+		{
+			name:       "node=node-1 is specified for namespace testns",
+			namespaces: []string{testns},
+			node:       "node-1",
+			expected:   "cluster_node_node_1",
+		},
+		{
+			name:       "Empty node is specified for namespace testns",
+			namespaces: []string{testns},
+			node:       "",
+			expected:   "cluster_unscheduled",
+		},
+		{
+			name:       "node=node-1 is specified for namespaces testns and testns2",
+			namespaces: []string{testns, testns2},
+			node:       "node-1",
+			expected:   "cluster_ns_testns_node_node_1",
+		},
+		{
+			name:       "Empty node is specified for namespaces testns and testns2",
+			namespaces: []string{testns, testns2},
+			node:       "",
+			expected:   "cluster_ns_testns_unscheduled",
+		},
+	}
+
+	for _, tc := range testCases {
+		g := prepTestGraphForNamespaces(t, tc.namespaces)
+		name := g.nodeClusterName(testns, tc.node)
+		if tc.expected != name {
+			t.Fatalf("[%s] nodeClusterName doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, name)
+		}
+	}
+}
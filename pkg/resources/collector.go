@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+const (
+	// defaultWorkers is the number of concurrent List calls if Options.Workers isn't specified
+	defaultWorkers = 4
+)
+
+// Options represents the options to get resources
+type Options struct {
+	// DynamicClient is the client to get ExtraKinds
+	DynamicClient dynamic.Interface
+	// MetadataClient is the client to get only the metadata of secrets, not to get their data.
+	// Secrets are got with the clientset and their data is dropped, if it isn't specified.
+	MetadataClient metadata.Interface
+	// ExtraKinds are the kinds to get in addition to the built-in kinds, like rollouts.argoproj.io
+	ExtraKinds []string
+	// LabelSelector is the label selector to filter resources, like app.kubernetes.io/instance=foo
+	// Resources related to the selected resources, like owners, are also got.
+	LabelSelector string
+	// FieldSelector is the field selector to filter resources, like metadata.name=foo
+	// Resources related to the selected resources, like owners, are also got.
+	FieldSelector string
+	// Workers is the number of concurrent List calls
+	Workers int
+	// SkipForbidden skips the kinds that are forbidden or unsupported, instead of failing.
+	// The skipped kinds are stored in Resources.Invisible.
+	SkipForbidden bool
+	// EndpointSlices gets EndpointSlices, to find the pods that services actually send traffic to
+	EndpointSlices bool
+	// Storage gets the PersistentVolumes bound to PersistentVolumeClaims and the StorageClasses,
+	// which are cluster-scoped and need the permission to list them in the cluster
+	Storage bool
+	// PageSize is the number of objects in a List response, and the rest are got in the following pages
+	PageSize int64
+}
+
+// Collector collects k8s resources in namespaces
+type Collector struct {
+	clientset kubernetes.Interface
+	opts      Options
+}
+
+// KindError represents the error on getting resources of a kind
+type KindError struct {
+	Kind string
+	Err  error
+}
+
+// CollectError represents the errors on getting resources of some kinds in a namespace
+type CollectError struct {
+	Namespace string
+	Errors    []KindError
+}
+
+// Error returns the combined message of the errors for all the kinds
+func (e *CollectError) Error() string {
+	kinds := []string{}
+	msgs := []string{}
+	for _, ke := range e.Errors {
+		kinds = append(kinds, ke.Kind)
+		msgs = append(msgs, fmt.Sprintf("%s: %v", ke.Kind, ke.Err))
+	}
+	return fmt.Sprintf("failed to get %s in namespace %q: %s", strings.Join(kinds, ", "), e.Namespace, strings.Join(msgs, "; "))
+}
+
+// listTask represents a List call for a kind
+type listTask struct {
+	kind string
+	list func(ctx context.Context) error
+}
+
+// NewCollector returns a Collector with the options
+func NewCollector(clientset kubernetes.Interface, opts Options) *Collector {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	return &Collector{clientset: clientset, opts: opts}
+}
+
+// Collect returns Resources for the namespace
+// Resources of each kind are listed concurrently by the workers, until ctx is done.
+// If it fails to list some kinds, it returns *CollectError that has errors for all of them.
+func (c *Collector) Collect(ctx context.Context, namespace string) (*Resources, error) {
+	res := &Resources{clientset: c.clientset, Namespace: namespace, Registered: map[string]*unstructured.UnstructuredList{}}
+	tasks := c.listTasks(res, metav1.ListOptions{LabelSelector: c.opts.LabelSelector, FieldSelector: c.opts.FieldSelector, Limit: c.opts.PageSize})
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[string]error{}
+	)
+	ch := make(chan listTask)
+	for i := 0; i < c.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range ch {
+				if err := task.list(ctx); err != nil {
+					mu.Lock()
+					errs[task.kind] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, task := range tasks {
+		ch <- task
+	}
+	close(ch)
+	wg.Wait()
+
+	// Keep the order of the tasks for the errors
+	collectErr := &CollectError{Namespace: namespace}
+	for _, task := range tasks {
+		err, ok := errs[task.kind]
+		if !ok {
+			continue
+		}
+		// Secrets are forbidden for read-only roles, like view, so they are skipped even without SkipForbidden
+		if (c.opts.SkipForbidden || task.kind == "secret") && isForbiddenOrUnsupported(err) {
+			fmt.Fprintf(os.Stderr, "Skipping %s in namespace %q: %v\n", task.kind, namespace, err)
+			res.Invisible = append(res.Invisible, task.kind)
+			continue
+		}
+		collectErr.Errors = append(collectErr.Errors, KindError{Kind: task.kind, Err: err})
+	}
+	if len(collectErr.Errors) > 0 {
+		return nil, collectErr
+	}
+	// Lists for the skipped kinds are left empty, and the skipped extra kinds are removed not to be drawn
+	res.removeInvisibleExtras()
+	res.ensureLists()
+	res.removeOldRss()
+
+	// Labels of the namespace are only used for namespaceSelector, so failing to get them isn't fatal
+	if nsObj, err := c.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err == nil {
+		res.NamespaceLabels = nsObj.Labels
+	} else {
+		fmt.Fprintf(os.Stderr, "Failed to get labels of namespace %q: %v\n", namespace, err)
+	}
+
+	// related resources that don't match the selectors
+	if c.opts.LabelSelector != "" || c.opts.FieldSelector != "" {
+		res.addRelated(ctx, Clients{Clientset: c.clientset, DynamicClient: c.opts.DynamicClient, MetadataClient: c.opts.MetadataClient})
+	}
+
+	return res, nil
+}
+
+// isForbiddenOrUnsupported returns true if err is caused by the lack of permission
+// or the lack of support for the kind in the k8s cluster. Wrapped errors are also checked.
+func isForbiddenOrUnsupported(err error) bool {
+	var noResource *meta.NoResourceMatchError
+	var noKind *meta.NoKindMatchError
+	return apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) ||
+		errors.As(err, &noResource) || errors.As(err, &noKind)
+}
+
+// listTasks returns the List calls for all kinds, which store the results to res
+func (c *Collector) listTasks(res *Resources, listOpts metav1.ListOptions) []listTask {
+	cs := c.clientset
+	ns := res.Namespace
+
+	clients := Clients{Clientset: cs, DynamicClient: c.opts.DynamicClient, MetadataClient: c.opts.MetadataClient}
+
+	tasks := []listTask{}
+	for _, kind := range Kinds() {
+		kind := kind
+		tasks = append(tasks, listTask{kind.Name, func(ctx context.Context) error {
+			return kind.List(ctx, clients, res, listOpts)
+		}})
+	}
+
+	// Each extra kind is listed separately, so that it can fail or be skipped by itself
+	res.Extras = []*ExtraResources{}
+	for _, ek := range resolveExtras(cs.Discovery(), c.opts.DynamicClient, c.opts.ExtraKinds) {
+		ek := ek
+		if ek.extra != nil {
+			res.Extras = append(res.Extras, ek.extra)
+		}
+		tasks = append(tasks, listTask{ek.name, func(ctx context.Context) error {
+			if ek.err != nil {
+				return ek.err
+			}
+			return listExtra(ctx, c.opts.DynamicClient, ns, ek.extra, listOpts)
+		}})
+	}
+
+	for _, kind := range enabledAuxiliaryKinds(c.opts) {
+		kind := kind
+		tasks = append(tasks, listTask{kind.Name, func(ctx context.Context) error {
+			return kind.List(ctx, clients, res, listOpts)
+		}})
+	}
+
+	return tasks
+}
@@ -0,0 +1,306 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCollect(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failResources []string
+		workers       int
+		expectedKinds []string
+	}{
+		{
+			name:          "No List calls fail",
+			failResources: []string{},
+			expectedKinds: []string{},
+		},
+		{
+			name:          "List call for services fails",
+			failResources: []string{"services"},
+			expectedKinds: []string{"svc"},
+		},
+		{
+			name:          "List calls for services and hpas fail with one worker",
+			failResources: []string{"services", "horizontalpodautoscalers"},
+			workers:       1,
+			expectedKinds: []string{"svc", "hpa"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cs := fake.NewSimpleClientset(testRes1...)
+		for _, resource := range tc.failResources {
+			cs.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("injected error")
+			})
+		}
+
+		res, err := NewCollector(cs, Options{Workers: tc.workers}).Collect(context.TODO(), testns)
+		if len(tc.expectedKinds) == 0 {
+			if err != nil {
+				t.Fatalf("[%s] Collect failed: %v", tc.name, err)
+			}
+			if !res.HasResource("pod", "pod1") {
+				t.Fatalf("[%s] Collect doesn't return pod1", tc.name)
+			}
+			continue
+		}
+
+		collectErr, ok := err.(*CollectError)
+		if !ok {
+			t.Fatalf("[%s] Collect doesn't return CollectError, returned: %v", tc.name, err)
+		}
+		kinds := []string{}
+		for _, ke := range collectErr.Errors {
+			kinds = append(kinds, ke.Kind)
+		}
+		if strings.Join(tc.expectedKinds, ",") != strings.Join(kinds, ",") {
+			t.Fatalf("[%s] Collect doesn't return expected errors, expected:%v, returned:%v", tc.name, tc.expectedKinds, kinds)
+		}
+	}
+}
+
+func TestCollectExtraErrors(t *testing.T) {
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	dc := NewOfflineDynamicClient(objs).(*dynamicfake.FakeDynamicClient)
+	dc.PrependReactor("list", "rollouts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("injected error")
+	})
+
+	// Errors are reported for each extra kind that fails, not for all the extra kinds
+	_, err = NewCollector(NewOfflineClientset(objs), Options{DynamicClient: dc, ExtraKinds: []string{"rollouts.argoproj.io", "unknowns.example.com", "widgets.example.com"}}).Collect(context.TODO(), testns)
+	collectErr, ok := err.(*CollectError)
+	if !ok {
+		t.Fatalf("Collect doesn't return CollectError, returned: %v", err)
+	}
+	kinds := []string{}
+	for _, ke := range collectErr.Errors {
+		kinds = append(kinds, ke.Kind)
+	}
+	if expected := "rollout.argoproj.io,widgets.example.com"; strings.Join(kinds, ",") != expected {
+		t.Fatalf("Collect doesn't return expected errors, expected:%v, returned:%v", expected, kinds)
+	}
+}
+
+func TestCollectSkipForbidden(t *testing.T) {
+	testCases := []struct {
+		name              string
+		errs              map[string]error
+		expectedInvisible []string
+		expectedErrKinds  []string
+	}{
+		{
+			name: "List call for hpas is forbidden",
+			errs: map[string]error{
+				"horizontalpodautoscalers": apierrors.NewForbidden(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "", errors.New("injected error")),
+			},
+			expectedInvisible: []string{"hpa"},
+		},
+		{
+			name: "List calls for services are forbidden and cronjobs are unsupported",
+			errs: map[string]error{
+				"services": apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "", errors.New("injected error")),
+				"cronjobs": apierrors.NewNotFound(schema.GroupResource{Group: "batch", Resource: "cronjobs"}, ""),
+			},
+			expectedInvisible: []string{"svc", "cronjob"},
+		},
+		{
+			name: "List call for hpas is forbidden and pods fails",
+			errs: map[string]error{
+				"horizontalpodautoscalers": apierrors.NewForbidden(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "", errors.New("injected error")),
+				"pods":                     errors.New("injected error"),
+			},
+			expectedErrKinds: []string{"pod"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cs := fake.NewSimpleClientset(testRes1...)
+		for resource, err := range tc.errs {
+			err := err
+			cs.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, err
+			})
+		}
+
+		res, err := NewCollector(cs, Options{SkipForbidden: true}).Collect(context.TODO(), testns)
+		if len(tc.expectedErrKinds) > 0 {
+			collectErr, ok := err.(*CollectError)
+			if !ok {
+				t.Fatalf("[%s] Collect doesn't return CollectError, returned: %v", tc.name, err)
+			}
+			kinds := []string{}
+			for _, ke := range collectErr.Errors {
+				kinds = append(kinds, ke.Kind)
+			}
+			if strings.Join(tc.expectedErrKinds, ",") != strings.Join(kinds, ",") {
+				t.Fatalf("[%s] Collect doesn't return expected errors, expected:%v, returned:%v", tc.name, tc.expectedErrKinds, kinds)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("[%s] Collect failed: %v", tc.name, err)
+		}
+		if strings.Join(tc.expectedInvisible, ",") != strings.Join(res.Invisible, ",") {
+			t.Fatalf("[%s] Collect doesn't return expected invisible kinds, expected:%v, returned:%v", tc.name, tc.expectedInvisible, res.Invisible)
+		}
+		for _, kind := range tc.expectedInvisible {
+			if names := res.GetResourceNames(kind); len(names) != 0 {
+				t.Fatalf("[%s] Collect doesn't return empty list for %s, returned:%v", tc.name, kind, names)
+			}
+		}
+		if !res.HasResource("pod", "pod1") {
+			t.Fatalf("[%s] Collect doesn't return pod1", tc.name)
+		}
+	}
+}
+
+func TestCollectSecretsForbidden(t *testing.T) {
+	cs := fake.NewSimpleClientset(testRes1...)
+	cs.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("injected error"))
+	})
+
+	// Secrets are skipped even without SkipForbidden
+	res, err := NewCollector(cs, Options{}).Collect(context.TODO(), testns)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if strings.Join(res.Invisible, ",") != "secret" {
+		t.Fatalf("Collect doesn't return expected invisible kinds, expected:[secret], returned:%v", res.Invisible)
+	}
+	if !res.HasResource("pod", "pod1") {
+		t.Fatalf("Collect doesn't return pod1")
+	}
+
+	// Other kinds still fail
+	cs.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("injected error"))
+	})
+	if _, err := NewCollector(cs, Options{}).Collect(context.TODO(), testns); err == nil {
+		t.Fatalf("Collect expects error for forbidden pods, but returned no error")
+	}
+}
+
+func TestCollectCanceled(t *testing.T) {
+	cs := fake.NewSimpleClientset(testRes1...)
+	cs.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, context.Canceled
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err := NewCollector(cs, Options{}).Collect(ctx, testns)
+	if err == nil {
+		t.Fatalf("Collect expects error for canceled context, but returned no error")
+	}
+	if !strings.Contains(err.Error(), "svc") || !strings.Contains(err.Error(), "hpa") {
+		t.Fatalf("Collect doesn't return error for all kinds, returned: %v", err)
+	}
+}
+
+func TestCollectEndpointSlices(t *testing.T) {
+	slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "svc1-abcde",
+		Labels: map[string]string{discoveryv1.LabelServiceName: "svc1"}}}
+
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected int
+	}{
+		{
+			name:     "EndpointSlices aren't collected by default",
+			opts:     Options{},
+			expected: 0,
+		},
+		{
+			name:     "EndpointSlices are collected",
+			opts:     Options{EndpointSlices: true},
+			expected: 1,
+		},
+		{
+			name:     "EndpointSlices are collected regardless of label selector",
+			opts:     Options{EndpointSlices: true, LabelSelector: "app=unknown"},
+			expected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		cs := fake.NewSimpleClientset(append([]runtime.Object{slice}, testRes1...)...)
+		res, err := NewCollector(cs, tc.opts).Collect(context.TODO(), testns)
+		if err != nil {
+			t.Fatalf("[%s] Collect failed: %v", tc.name, err)
+		}
+		if len(res.EndpointSlices.Items) != tc.expected {
+			t.Fatalf("[%s] Collect doesn't return expected endpointslices, expected:%v, returned:%v", tc.name, tc.expected, len(res.EndpointSlices.Items))
+		}
+	}
+}
+
+func TestCollectStorage(t *testing.T) {
+	objs := []runtime.Object{
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"},
+			Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: testns, Name: "pvc1"}}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv2"},
+			Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: nontestns, Name: "pvc1"}}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv3"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}},
+	}
+
+	testCases := []struct {
+		name        string
+		opts        Options
+		expectedPvs []string
+		expectedScs []string
+	}{
+		{
+			name:        "PersistentVolumes and StorageClasses aren't collected by default",
+			opts:        Options{},
+			expectedPvs: []string{},
+			expectedScs: []string{},
+		},
+		{
+			name:        "PersistentVolumes claimed from the namespace and StorageClasses are collected",
+			opts:        Options{Storage: true},
+			expectedPvs: []string{"pv1"},
+			expectedScs: []string{"standard"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cs := fake.NewSimpleClientset(append(objs, testRes1...)...)
+		res, err := NewCollector(cs, tc.opts).Collect(context.TODO(), testns)
+		if err != nil {
+			t.Fatalf("[%s] Collect failed: %v", tc.name, err)
+		}
+		if pvs := listNames(res.Pvs); strings.Join(pvs, ",") != strings.Join(tc.expectedPvs, ",") {
+			t.Fatalf("[%s] Collect doesn't return expected pvs, expected:%v, returned:%v", tc.name, tc.expectedPvs, pvs)
+		}
+		if scs := listNames(res.StorageClasses); strings.Join(scs, ",") != strings.Join(tc.expectedScs, ",") {
+			t.Fatalf("[%s] Collect doesn't return expected storageclasses, expected:%v, returned:%v", tc.name, tc.expectedScs, scs)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// secretsResource is the resource of secrets, to get only their metadata with the metadata client
+var secretsResource = corev1.SchemeGroupVersion.WithResource("secrets")
+
+// ConfigMapNames returns the names of the configmaps that the pod spec refers to
+// through volumes, projected volumes, env.valueFrom and envFrom.
+func ConfigMapNames(spec *corev1.PodSpec) []string {
+	names := newNameSet()
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			names.add(vol.ConfigMap.Name)
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					names.add(src.ConfigMap.Name)
+				}
+			}
+		}
+	}
+	for _, c := range allContainers(spec) {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+				names.add(env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				names.add(envFrom.ConfigMapRef.Name)
+			}
+		}
+	}
+	return names.list
+}
+
+// SecretNames returns the names of the secrets that the pod spec refers to
+// through volumes, projected volumes, env.valueFrom, envFrom and imagePullSecrets.
+func SecretNames(spec *corev1.PodSpec) []string {
+	names := newNameSet()
+	for _, vol := range spec.Volumes {
+		if vol.Secret != nil {
+			names.add(vol.Secret.SecretName)
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.Secret != nil {
+					names.add(src.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, c := range allContainers(spec) {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names.add(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.SecretRef != nil {
+				names.add(envFrom.SecretRef.Name)
+			}
+		}
+	}
+	for _, ref := range spec.ImagePullSecrets {
+		names.add(ref.Name)
+	}
+	return names.list
+}
+
+// ServiceAccountName returns the name of the serviceaccount that the pod spec uses
+// It is "default" if it isn't specified, like the pods that the serviceaccount admission
+// controller doesn't handle yet.
+func ServiceAccountName(spec *corev1.PodSpec) string {
+	if spec.ServiceAccountName != "" {
+		return spec.ServiceAccountName
+	}
+	if spec.DeprecatedServiceAccount != "" {
+		return spec.DeprecatedServiceAccount
+	}
+	return "default"
+}
+
+// allContainers returns the init containers and the containers in the pod spec
+func allContainers(spec *corev1.PodSpec) []corev1.Container {
+	containers := []corev1.Container{}
+	containers = append(containers, spec.InitContainers...)
+	return append(containers, spec.Containers...)
+}
+
+// stripSecretData removes the data of the secrets, which isn't needed to draw them,
+// so that it isn't kept in memory or written to snapshots.
+// The last applied configuration is also removed, because it can contain the data.
+func stripSecretData(secrets []corev1.Secret) {
+	for i := range secrets {
+		secrets[i].Data = nil
+		secrets[i].StringData = nil
+		secrets[i].Annotations = withoutLastAppliedConfig(secrets[i].Annotations)
+	}
+}
+
+// listSecrets stores the secrets in the namespace of res to res.Secrets without their data.
+// Only the metadata of the secrets is got with the metadata client, if it's available,
+// so that the data isn't even sent from the k8s cluster.
+func listSecrets(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+	res.Secrets = &corev1.SecretList{}
+	if clients.MetadataClient == nil {
+		err := listAll(ctx, res.Secrets, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return clients.Clientset.CoreV1().Secrets(res.Namespace).List(ctx, opts)
+		})
+		stripSecretData(res.Secrets.Items)
+		return err
+	}
+
+	list := &metav1.PartialObjectMetadataList{}
+	err := listAll(ctx, list, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return clients.MetadataClient.Resource(secretsResource).Namespace(res.Namespace).List(ctx, opts)
+	})
+	for i := range list.Items {
+		res.Secrets.Items = append(res.Secrets.Items, secretFromMetadata(&list.Items[i]))
+	}
+	return err
+}
+
+// getSecret returns the secret in the namespace without its data, like listSecrets
+func getSecret(ctx context.Context, clients Clients, namespace, name string) (*corev1.Secret, error) {
+	if clients.MetadataClient == nil {
+		secret, err := clients.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		secrets := []corev1.Secret{*secret}
+		stripSecretData(secrets)
+		return &secrets[0], nil
+	}
+
+	obj, err := clients.MetadataClient.Resource(secretsResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := stripUnusedFields(obj); err != nil {
+		return nil, err
+	}
+	secret := secretFromMetadata(obj)
+	return &secret, nil
+}
+
+// fillSecrets stores objs, which are got from the informer for secrets or for their metadata,
+// to res.Secrets without their data
+func fillSecrets(res *Resources, objs []interface{}) {
+	res.Secrets = &corev1.SecretList{}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *metav1.PartialObjectMetadata:
+			res.Secrets.Items = append(res.Secrets.Items, secretFromMetadata(o))
+		case *corev1.Secret:
+			res.Secrets.Items = append(res.Secrets.Items, *o)
+		}
+	}
+	stripSecretData(res.Secrets.Items)
+}
+
+// secretFromMetadata returns the secret that has only the metadata in obj
+func secretFromMetadata(obj *metav1.PartialObjectMetadata) corev1.Secret {
+	return corev1.Secret{ObjectMeta: obj.ObjectMeta}
+}
+
+// nameSet is the list of names without duplicates, in the order of addition
+type nameSet struct {
+	list []string
+	seen map[string]bool
+}
+
+func newNameSet() *nameSet {
+	return &nameSet{list: []string{}, seen: map[string]bool{}}
+}
+
+func (s *nameSet) add(name string) {
+	if name == "" || s.seen[name] {
+		return
+	}
+	s.seen[name] = true
+	s.list = append(s.list, name)
+}
@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestConfigNames(t *testing.T) {
+	testCases := []struct {
+		name            string
+		spec            corev1.PodSpec
+		expectedCms     []string
+		expectedSecrets []string
+	}{
+		{
+			name:            "No references",
+			spec:            corev1.PodSpec{},
+			expectedCms:     []string{},
+			expectedSecrets: []string{},
+		},
+		{
+			name: "References through volumes and projected volumes",
+			spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{Name: "vol1", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm1"}}}},
+					{Name: "vol2", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "secret1"}}},
+					{Name: "vol3", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+						{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "cm2"}}},
+						{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "secret2"}}},
+					}}}},
+				},
+			},
+			expectedCms:     []string{"cm1", "cm2"},
+			expectedSecrets: []string{"secret1", "secret2"},
+		},
+		{
+			name: "References through env, envFrom and imagePullSecrets without duplicates",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "c1",
+					EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm1"}}}},
+				}},
+				Containers: []corev1.Container{{Name: "c2",
+					Env: []corev1.EnvVar{
+						{Name: "ENV1", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "cm1"}}}},
+						{Name: "ENV2", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "secret1"}}}},
+					},
+					EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret2"}}}},
+				}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "secret3"}},
+			},
+			expectedCms:     []string{"cm1"},
+			expectedSecrets: []string{"secret1", "secret2", "secret3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cms := ConfigMapNames(&tc.spec)
+		if strings.Join(tc.expectedCms, ",") != strings.Join(cms, ",") {
+			t.Fatalf("[%s] ConfigMapNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expectedCms, cms)
+		}
+		secrets := SecretNames(&tc.spec)
+		if strings.Join(tc.expectedSecrets, ",") != strings.Join(secrets, ",") {
+			t.Fatalf("[%s] SecretNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expectedSecrets, secrets)
+		}
+	}
+}
+
+func TestServiceAccountName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     corev1.PodSpec
+		expected string
+	}{
+		{
+			name:     "serviceAccountName is specified",
+			spec:     corev1.PodSpec{ServiceAccountName: "sa1", DeprecatedServiceAccount: "sa2"},
+			expected: "sa1",
+		},
+		{
+			name:     "Only deprecated serviceAccount is specified",
+			spec:     corev1.PodSpec{DeprecatedServiceAccount: "sa2"},
+			expected: "sa2",
+		},
+		{
+			name:     "No serviceaccount is specified",
+			spec:     corev1.PodSpec{},
+			expected: "default",
+		},
+	}
+
+	for _, tc := range testCases {
+		name := ServiceAccountName(&tc.spec)
+		if tc.expected != name {
+			t.Fatalf("[%s] ServiceAccountName doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, name)
+		}
+	}
+}
+
+func TestSecretDataStripped(t *testing.T) {
+	cs := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "secret1",
+			Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "foo": "bar"}},
+		Data:       map[string][]byte{"key1": []byte("value1")},
+		StringData: map[string]string{"key2": "value2"},
+	})
+
+	res, err := NewResources(cs, testns)
+	if err != nil {
+		t.Fatalf("NewResources failed: %v", err)
+	}
+	if len(res.Secrets.Items) != 1 {
+		t.Fatalf("NewResources doesn't return secret1, returned:%v", res.GetResourceNames("secret"))
+	}
+	secret := res.Secrets.Items[0]
+	if secret.Data != nil || secret.StringData != nil {
+		t.Fatalf("NewResources doesn't strip data of secret1, returned:%v, %v", secret.Data, secret.StringData)
+	}
+	if _, ok := secret.Annotations[corev1.LastAppliedConfigAnnotation]; ok || secret.Annotations["foo"] != "bar" {
+		t.Fatalf("NewResources doesn't strip only last applied configuration of secret1, returned:%v", secret.Annotations)
+	}
+}
+
+func TestSecretMetadataOnly(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1", Labels: map[string]string{"app": "foo"}},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "vol1", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "secret2"}}},
+		}}}
+	cs := fake.NewSimpleClientset(pod)
+	// Secrets are only got through the metadata client
+	cs.PrependReactor("*", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("secrets are got with the clientset")
+	})
+	secretMeta := func(name string, labels map[string]string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: name, Labels: labels,
+				Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "foo": "bar"}}}
+	}
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatalf("AddMetaToScheme failed: %v", err)
+	}
+	mc := metadatafake.NewSimpleMetadataClient(scheme, secretMeta("secret1", map[string]string{"app": "foo"}), secretMeta("secret2", nil))
+
+	// secret1 is listed, and secret2 is got as the one used by pod1
+	res, err := NewCollector(cs, Options{MetadataClient: mc, LabelSelector: "app=foo"}).Collect(context.TODO(), testns)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if names := res.GetResourceNames("secret"); strings.Join(names, ",") != "secret1,secret2" {
+		t.Fatalf("Collect doesn't return expected secrets, expected:[secret1 secret2], returned:%v", names)
+	}
+	for _, secret := range res.Secrets.Items {
+		if _, ok := secret.Annotations[corev1.LastAppliedConfigAnnotation]; ok || secret.Annotations["foo"] != "bar" {
+			t.Fatalf("Collect doesn't strip only last applied configuration of %s, returned:%v", secret.Name, secret.Annotations)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ContainerKind represents the kind of a container in a pod
+type ContainerKind string
+
+const (
+	// ContainerKindInit is for the init containers, which run before the regular containers
+	ContainerKindInit ContainerKind = "init"
+	// ContainerKindRegular is for the regular containers, including the sidecars injected to them
+	ContainerKindRegular ContainerKind = ""
+	// ContainerKindEphemeral is for the ephemeral containers, which are added for debugging
+	ContainerKindEphemeral ContainerKind = "ephemeral"
+)
+
+// Container represents a container in a pod with its status
+type Container struct {
+	Kind ContainerKind
+	Name string
+	// Image is the image that the container is running, or the one in the spec if it isn't reported
+	Image    string
+	Ports    []corev1.ContainerPort
+	Restarts int32
+}
+
+// PodContainers returns the init, regular and ephemeral containers in the pod, in this order
+func PodContainers(pod *corev1.Pod) []Container {
+	containers := []Container{}
+	for _, c := range pod.Spec.InitContainers {
+		containers = append(containers, newContainer(ContainerKindInit, c.Name, c.Image, c.Ports, pod.Status.InitContainerStatuses))
+	}
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, newContainer(ContainerKindRegular, c.Name, c.Image, c.Ports, pod.Status.ContainerStatuses))
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		containers = append(containers, newContainer(ContainerKindEphemeral, c.Name, c.Image, c.Ports, pod.Status.EphemeralContainerStatuses))
+	}
+	return containers
+}
+
+// newContainer returns the Container with the status for the name in statuses
+// Images reported only by their IDs, like sha256:0123..., are replaced with the ones in the spec.
+func newContainer(kind ContainerKind, name, image string, ports []corev1.ContainerPort, statuses []corev1.ContainerStatus) Container {
+	c := Container{Kind: kind, Name: name, Image: image, Ports: ports}
+	for _, status := range statuses {
+		if status.Name != name {
+			continue
+		}
+		if status.Image != "" && !strings.HasPrefix(status.Image, "sha256:") {
+			c.Image = status.Image
+		}
+		c.Restarts = status.RestartCount
+	}
+	return c
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "istio-init", Image: "istio/proxyv2:1.20.0"}},
+			Containers: []corev1.Container{
+				{Name: "app", Image: "app:v1", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+				{Name: "istio-proxy", Image: "istio/proxyv2:1.20.0"},
+			},
+			EphemeralContainers: []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}}},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "istio-init", Image: "docker.io/istio/proxyv2:1.20.0"}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.20.0", RestartCount: 1},
+				{Name: "app", Image: "sha256:0123456789abcdef", RestartCount: 3},
+			},
+		},
+	}
+
+	expected := []string{
+		"init istio-init docker.io/istio/proxyv2:1.20.0 [] 0",
+		" app app:v1 [8080] 3",
+		" istio-proxy docker.io/istio/proxyv2:1.20.0 [] 1",
+		"ephemeral debugger busybox [] 0",
+	}
+	containers := PodContainers(pod)
+	if len(containers) != len(expected) {
+		t.Fatalf("PodContainers doesn't return expected, expected:%v, returned:%v", expected, containers)
+	}
+	for i, c := range containers {
+		ports := []int32{}
+		for _, port := range c.Ports {
+			ports = append(ports, port.ContainerPort)
+		}
+		if returned := fmt.Sprintf("%s %s %s %v %d", c.Kind, c.Name, c.Image, ports, c.Restarts); returned != expected[i] {
+			t.Fatalf("PodContainers doesn't return expected, expected:%v, returned:%v", expected[i], returned)
+		}
+	}
+}
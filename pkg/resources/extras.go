@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// ExtraResources represents the resources of a kind that isn't built in this tool, like CRD
+type ExtraResources struct {
+	// Kind is the kind of the resources, like Rollout
+	Kind string `json:"kind"`
+	// Resource is the resource used to list the resources
+	Resource schema.GroupVersionResource `json:"resource"`
+	// List is the list of the resources
+	List *unstructured.UnstructuredList `json:"list"`
+}
+
+// Name returns the normalized name of the kind of the extra resources
+// It is the lower-cased kind followed by the group, to avoid conflicts with built-in kinds.
+// ex) rollout.argoproj.io
+func (e *ExtraResources) Name() string {
+	return extraName(e.Resource.Group, e.Kind)
+}
+
+// extraName returns the normalized name of the extra kind in the group
+func extraName(group, kind string) string {
+	name := strings.ToLower(kind)
+	if group == "" {
+		return name
+	}
+	return name + "." + group
+}
+
+// extraKind represents a kind requested as an extra kind, which is listed separately
+type extraKind struct {
+	// name is the normalized name of the kind, or the requested name if it can't be resolved
+	name string
+	// extra is the resources to store the listed objects, or nil if the kind can't be resolved
+	extra *ExtraResources
+	// err is the error on resolving the kind
+	err error
+}
+
+// resolveExtras resolves kinds to the resources through discovery, so that plural,
+// singular and short names can be used, like rollouts.argoproj.io or ro.
+// Kinds that aren't namespaced are skipped, and kinds resolved to the same resource are returned once.
+func resolveExtras(dc discovery.DiscoveryInterface, client dynamic.Interface, kinds []string) []extraKind {
+	extras := []extraKind{}
+	if len(kinds) == 0 {
+		return extras
+	}
+	if client == nil {
+		for _, kind := range kinds {
+			extras = append(extras, extraKind{name: kind, err: fmt.Errorf("dynamic client is required to get %q", kind)})
+		}
+		return extras
+	}
+
+	grs, err := restmapper.GetAPIGroupResources(dc)
+	if err != nil {
+		for _, kind := range kinds {
+			extras = append(extras, extraKind{name: kind, err: fmt.Errorf("failed to discover api resources: %w", err)})
+		}
+		return extras
+	}
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDiscoveryRESTMapper(grs), dc)
+
+	seen := map[string]bool{}
+	for _, kind := range kinds {
+		gvr, err := mapper.ResourceFor(schema.ParseGroupResource(kind).WithVersion(""))
+		if err != nil {
+			extras = append(extras, extraKind{name: kind, err: fmt.Errorf("failed to find resource for %q: %w", kind, err)})
+			continue
+		}
+		gvk, err := mapper.KindFor(gvr)
+		if err != nil {
+			extras = append(extras, extraKind{name: kind, err: fmt.Errorf("failed to find kind for %q: %w", kind, err)})
+			continue
+		}
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			extras = append(extras, extraKind{name: kind, err: fmt.Errorf("failed to find mapping for %q: %w", kind, err)})
+			continue
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			fmt.Fprintf(os.Stderr, "Skipping %q, because it isn't namespaced\n", kind)
+			continue
+		}
+
+		extra := &ExtraResources{Kind: gvk.Kind, Resource: gvr, List: newUnstructuredList()}
+		if seen[extra.Name()] {
+			continue
+		}
+		seen[extra.Name()] = true
+		extras = append(extras, extraKind{name: extra.Name(), extra: extra})
+	}
+
+	return extras
+}
+
+// listExtra lists the objects of the extra resources in the namespace into extra.List
+func listExtra(ctx context.Context, client dynamic.Interface, namespace string, extra *ExtraResources, listOpts metav1.ListOptions) error {
+	err := listAll(ctx, extra.List, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return client.Resource(extra.Resource).Namespace(namespace).List(ctx, opts)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get %s in namespace %q: %w", extra.Resource.GroupResource(), namespace, err)
+	}
+	return nil
+}
+
+// removeInvisibleExtras removes the extra resources of the kinds that were skipped
+func (r *Resources) removeInvisibleExtras() {
+	invisible := map[string]bool{}
+	for _, kind := range r.Invisible {
+		invisible[kind] = true
+	}
+	extras := []*ExtraResources{}
+	for _, extra := range r.Extras {
+		if !invisible[extra.Name()] {
+			extras = append(extras, extra)
+		}
+	}
+	r.Extras = extras
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestExtras(t *testing.T) {
+	testCases := []struct {
+		name       string
+		extraKinds []string
+		kind       string
+		expected   []string
+		expectErr  bool
+	}{
+		{
+			name:       "No extra kinds are specified",
+			extraKinds: []string{},
+			kind:       "rollout.argoproj.io",
+			expected:   []string{},
+		},
+		{
+			name:       "Extra kind is specified with plural and group",
+			extraKinds: []string{"rollouts.argoproj.io"},
+			kind:       "rollout.argoproj.io",
+			expected:   []string{"rollout1"},
+		},
+		{
+			name:       "Extra kind is specified with singular",
+			extraKinds: []string{"rollout"},
+			kind:       "rollout.argoproj.io",
+			expected:   []string{"rollout1"},
+		},
+		{
+			name:       "Un-known extra kind is specified",
+			extraKinds: []string{"unknowns.argoproj.io"},
+			expectErr:  true,
+		},
+	}
+
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	for _, tc := range testCases {
+		res, err := NewResourcesWithOptions(NewOfflineClientset(objs), testns, Options{
+			DynamicClient: NewOfflineDynamicClient(objs),
+			ExtraKinds:    tc.extraKinds,
+		})
+		if tc.expectErr {
+			if err == nil {
+				t.Fatalf("[%s] NewResourcesWithOptions expects error, but returned no error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] NewResourcesWithOptions failed: %v", tc.name, err)
+		}
+
+		resNames := res.GetResourceNames(tc.kind)
+		if len(tc.expected) != len(resNames) {
+			t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, resNames)
+		}
+		for i := range tc.expected {
+			if tc.expected[i] != resNames[i] {
+				t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, resNames)
+			}
+		}
+	}
+}
+
+func TestExtrasSkipForbidden(t *testing.T) {
+	testCases := []struct {
+		name              string
+		extraKinds        []string
+		expectedInvisible []string
+		expectedVisible   []string
+	}{
+		{
+			name:              "List call for extra kind is forbidden",
+			extraKinds:        []string{"rollouts.argoproj.io"},
+			expectedInvisible: []string{"rollout.argoproj.io"},
+		},
+		{
+			name:              "Extra kind isn't installed",
+			extraKinds:        []string{"unknowns.argoproj.io"},
+			expectedInvisible: []string{"unknowns.argoproj.io"},
+		},
+		{
+			name:              "Forbidden extra kind doesn't hide the other extra kinds",
+			extraKinds:        []string{"rollouts.argoproj.io", "unknowns.example.com"},
+			expectedInvisible: []string{"rollout.argoproj.io"},
+			expectedVisible:   []string{"unknown.example.com"},
+		},
+	}
+
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	for _, tc := range testCases {
+		dc := NewOfflineDynamicClient(objs).(*dynamicfake.FakeDynamicClient)
+		dc.PrependReactor("list", "rollouts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "argoproj.io", Resource: "rollouts"}, "", errors.New("injected error"))
+		})
+
+		res, err := NewResourcesWithOptions(NewOfflineClientset(objs), testns, Options{DynamicClient: dc, ExtraKinds: tc.extraKinds, SkipForbidden: true})
+		if err != nil {
+			t.Fatalf("[%s] NewResourcesWithOptions failed: %v", tc.name, err)
+		}
+		if strings.Join(tc.expectedInvisible, ",") != strings.Join(res.Invisible, ",") {
+			t.Fatalf("[%s] NewResourcesWithOptions doesn't return expected invisible kinds, expected:%v, returned:%v", tc.name, tc.expectedInvisible, res.Invisible)
+		}
+		if !res.HasResource("deploy", "deploy1") {
+			t.Fatalf("[%s] NewResourcesWithOptions doesn't return deploy1", tc.name)
+		}
+		visible := []string{}
+		for _, extra := range res.Extras {
+			visible = append(visible, extra.Name())
+		}
+		if strings.Join(tc.expectedVisible, ",") != strings.Join(visible, ",") {
+			t.Fatalf("[%s] NewResourcesWithOptions doesn't return expected extra kinds, expected:%v, returned:%v", tc.name, tc.expectedVisible, visible)
+		}
+	}
+}
+
+func TestNormalizeKind(t *testing.T) {
+	testCases := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		expected   string
+		expectErr  bool
+	}{
+		{
+			name:       "Should return rollout.argoproj.io for Rollout in argoproj.io",
+			apiVersion: "argoproj.io/v1alpha1",
+			kind:       "Rollout",
+			expected:   "rollout.argoproj.io",
+		},
+		{
+			name:       "Should return error for Rollout in other group",
+			apiVersion: "example.com/v1",
+			kind:       "Rollout",
+			expectErr:  true,
+		},
+		{
+			name:       "Should return deploy for Deployment",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			expected:   "deploy",
+		},
+	}
+
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	res, err := NewResourcesWithOptions(NewOfflineClientset(objs), testns, Options{
+		DynamicClient: NewOfflineDynamicClient(objs),
+		ExtraKinds:    []string{"rollouts.argoproj.io"},
+	})
+	if err != nil {
+		t.Fatalf("NewResourcesWithOptions failed: %v", err)
+	}
+
+	for _, tc := range testCases {
+		normalized, err := res.NormalizeKind(tc.apiVersion, tc.kind)
+		if tc.expected != normalized {
+			t.Fatalf("[%s] NormalizeKind doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, normalized)
+		}
+		if tc.expectErr && err == nil {
+			t.Fatalf("[%s] NormalizeKind expects error, but returned no error", tc.name)
+		}
+		if !tc.expectErr && err != nil {
+			t.Fatalf("[%s] NormalizeKind expects no error, but returned error %v", tc.name, err)
+		}
+	}
+}
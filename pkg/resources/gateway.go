@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// GatewayGroup is the API group of Gateway API
+	GatewayGroup = "gateway.networking.k8s.io"
+)
+
+// gatewayKind represents a kind of Gateway API
+type gatewayKind struct {
+	kind    string
+	apiKind string
+	// versions are the versions to look for, in the order of preference
+	versions []string
+}
+
+// gatewayKinds are the kinds of Gateway API that are collected
+var gatewayKinds = []gatewayKind{
+	{"gtw", "Gateway", []string{"v1", "v1beta1"}},
+	{"httproute", "HTTPRoute", []string{"v1", "v1beta1"}},
+	{"grpcroute", "GRPCRoute", []string{"v1", "v1alpha2"}},
+	{"referencegrant", "ReferenceGrant", []string{"v1", "v1beta1", "v1alpha2"}},
+}
+
+// GatewayRef represents a reference in Gateway API objects, like parentRefs and backendRefs of routes
+type GatewayRef struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// gatewayResource returns the resource for the kind of Gateway API that the k8s cluster serves.
+// ok is false if it isn't served, like Gateway API CRDs aren't installed.
+// The resource is looked up by the kind, to use the name that the k8s cluster serves.
+func gatewayResource(dc discovery.DiscoveryInterface, gk gatewayKind) (gvr schema.GroupVersionResource, ok bool, err error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return gvr, false, fmt.Errorf("failed to discover api groups: %w", err)
+	}
+	served := map[string]bool{}
+	for _, group := range groups.Groups {
+		if group.Name != GatewayGroup {
+			continue
+		}
+		for _, v := range group.Versions {
+			served[v.Version] = true
+		}
+	}
+
+	for _, version := range gk.versions {
+		if !served[version] {
+			continue
+		}
+		list, err := dc.ServerResourcesForGroupVersion(GatewayGroup + "/" + version)
+		if err != nil {
+			return gvr, false, fmt.Errorf("failed to discover api resources for %s/%s: %w", GatewayGroup, version, err)
+		}
+		for _, r := range list.APIResources {
+			// Subresources, like gateways/status, have the same kind
+			if r.Kind == gk.apiKind && !strings.Contains(r.Name, "/") {
+				return schema.GroupVersionResource{Group: GatewayGroup, Version: version, Resource: r.Name}, true, nil
+			}
+		}
+	}
+	return gvr, false, nil
+}
+
+// getGatewayObjects returns the objects of the kind of Gateway API in the namespace
+// It returns an empty list if the k8s cluster doesn't serve the kind.
+func getGatewayObjects(ctx context.Context, dc discovery.DiscoveryInterface, client dynamic.Interface, namespace string, gk gatewayKind, listOpts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	if client == nil {
+		return newUnstructuredList(), nil
+	}
+	gvr, ok, err := gatewayResource(dc, gk)
+	if err != nil || !ok {
+		return newUnstructuredList(), err
+	}
+	list := newUnstructuredList()
+	err = listAll(ctx, list, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return client.Resource(gvr).Namespace(namespace).List(ctx, opts)
+	})
+	return list, err
+}
+
+// gatewayInformerFunc returns the function to get the informer for the kind of Gateway API through the dynamic client
+func gatewayInformerFunc(kind string) func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+	return func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+		if f.Dynamic == nil {
+			return nil, nil
+		}
+		for _, gk := range gatewayKinds {
+			if gk.kind != kind {
+				continue
+			}
+			gvr, ok, err := gatewayResource(clients.Clientset.Discovery(), gk)
+			if err != nil || !ok {
+				return nil, err
+			}
+			return f.Dynamic.ForResource(gvr).Informer(), nil
+		}
+		return nil, nil
+	}
+}
+
+// gatewayFillFunc returns the function to store the objects of the kind of Gateway API got from an informer to res
+func gatewayFillFunc(kind string) func(res *Resources, objs []interface{}) {
+	return func(res *Resources, objs []interface{}) {
+		list := newUnstructuredList()
+		fillList(list, objs)
+		*res.gatewayList(kind) = list
+	}
+}
+
+// gatewayList returns the pointer to the list in res for the kind of Gateway API
+func (r *Resources) gatewayList(kind string) **unstructured.UnstructuredList {
+	switch kind {
+	case "gtw":
+		return &r.Gateways
+	case "httproute":
+		return &r.HTTPRoutes
+	case "grpcroute":
+		return &r.GRPCRoutes
+	case "referencegrant":
+		return &r.ReferenceGrants
+	}
+	return nil
+}
+
+// newUnstructuredList returns an empty list that can be read back from JSON,
+// which requires the kind of the list.
+func newUnstructuredList() *unstructured.UnstructuredList {
+	return &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+}
+
+// RouteParentRefs returns the parentRefs of the route, like HTTPRoute and GRPCRoute
+// Group, kind and namespace are defaulted to Gateway in the namespace of the route.
+func RouteParentRefs(route *unstructured.Unstructured) []GatewayRef {
+	refs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	return gatewayRefs(refs, GatewayGroup, "Gateway", route.GetNamespace())
+}
+
+// RouteBackendRefs returns the backendRefs in all rules of the route, like HTTPRoute and GRPCRoute
+// Group, kind and namespace are defaulted to Service in the namespace of the route.
+func RouteBackendRefs(route *unstructured.Unstructured) []GatewayRef {
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	refs := []GatewayRef{}
+	for _, rule := range rules {
+		r, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backends, _, _ := unstructured.NestedSlice(r, "backendRefs")
+		refs = append(refs, gatewayRefs(backends, "", "Service", route.GetNamespace())...)
+	}
+	return refs
+}
+
+// gatewayRefs returns the references in refs with the defaults for the fields that aren't set
+func gatewayRefs(refs []interface{}, group, kind, namespace string) []GatewayRef {
+	ret := []GatewayRef{}
+	for _, ref := range refs {
+		m, ok := ref.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		gr := GatewayRef{Group: group, Kind: kind, Namespace: namespace, Name: stringField(m, "name")}
+		// group can be set to "" for the core group
+		if v, ok := m["group"].(string); ok {
+			gr.Group = v
+		}
+		if v := stringField(m, "kind"); v != "" {
+			gr.Kind = v
+		}
+		if v := stringField(m, "namespace"); v != "" {
+			gr.Namespace = v
+		}
+		ret = append(ret, gr)
+	}
+	return ret
+}
+
+// ReferenceGranted returns whether the reference from the object of the kind in the namespace to ref is allowed.
+// References in the same namespace are always allowed, and cross-namespace references are allowed
+// only if a ReferenceGrant in the namespace of ref allows them.
+func ReferenceGranted(ress []*Resources, fromGroup, fromKind, fromNamespace string, ref GatewayRef) bool {
+	if fromNamespace == ref.Namespace {
+		return true
+	}
+	for _, res := range ress {
+		if res.Namespace != ref.Namespace {
+			continue
+		}
+		for _, grant := range res.ReferenceGrants.Items {
+			if grantMatches(&grant, fromGroup, fromKind, fromNamespace, ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// grantMatches returns whether the ReferenceGrant allows the reference
+func grantMatches(grant *unstructured.Unstructured, fromGroup, fromKind, fromNamespace string, ref GatewayRef) bool {
+	froms, _, _ := unstructured.NestedSlice(grant.Object, "spec", "from")
+	fromOK := false
+	for _, from := range froms {
+		m, ok := from.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if stringField(m, "group") == fromGroup && stringField(m, "kind") == fromKind && stringField(m, "namespace") == fromNamespace {
+			fromOK = true
+			break
+		}
+	}
+	if !fromOK {
+		return false
+	}
+
+	tos, _, _ := unstructured.NestedSlice(grant.Object, "spec", "to")
+	for _, to := range tos {
+		m, ok := to.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// name is optional, and all objects of the kind are allowed without it
+		name := stringField(m, "name")
+		if stringField(m, "group") == ref.Group && stringField(m, "kind") == ref.Kind && (name == "" || name == ref.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringField returns the string field in m, or "" if it isn't a string
+func stringField(m map[string]interface{}, field string) string {
+	v, _ := m[field].(string)
+	return v
+}
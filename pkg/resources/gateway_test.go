@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	testRoute = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"namespace": testns, "name": "route1"},
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{
+				map[string]interface{}{"name": "gw1"},
+				map[string]interface{}{"name": "gw2", "namespace": nontestns},
+			},
+			"rules": []interface{}{
+				map[string]interface{}{"backendRefs": []interface{}{
+					map[string]interface{}{"name": "svc1", "port": int64(80)},
+				}},
+				map[string]interface{}{"backendRefs": []interface{}{
+					map[string]interface{}{"name": "svc2", "namespace": nontestns, "port": int64(80)},
+					map[string]interface{}{"group": "example.com", "kind": "Bucket", "name": "bucket1"},
+				}},
+			},
+		},
+	}}
+	testGateway = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"namespace": testns, "name": "gw1"},
+	}}
+)
+
+func refsString(refs []GatewayRef) []string {
+	ret := []string{}
+	for _, ref := range refs {
+		ret = append(ret, fmt.Sprintf("%s/%s/%s/%s", ref.Group, ref.Kind, ref.Namespace, ref.Name))
+	}
+	return ret
+}
+
+func TestRouteRefs(t *testing.T) {
+	expectedParents := []string{
+		"gateway.networking.k8s.io/Gateway/testns/gw1",
+		"gateway.networking.k8s.io/Gateway/nontestns/gw2",
+	}
+	if parents := refsString(RouteParentRefs(testRoute)); strings.Join(expectedParents, ",") != strings.Join(parents, ",") {
+		t.Fatalf("RouteParentRefs doesn't return expected, expected:%v, returned:%v", expectedParents, parents)
+	}
+
+	expectedBackends := []string{
+		"/Service/testns/svc1",
+		"/Service/nontestns/svc2",
+		"example.com/Bucket/testns/bucket1",
+	}
+	if backends := refsString(RouteBackendRefs(testRoute)); strings.Join(expectedBackends, ",") != strings.Join(backends, ",") {
+		t.Fatalf("RouteBackendRefs doesn't return expected, expected:%v, returned:%v", expectedBackends, backends)
+	}
+}
+
+func TestReferenceGranted(t *testing.T) {
+	grant := func(fromKind, toName string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1beta1",
+			"kind":       "ReferenceGrant",
+			"metadata":   map[string]interface{}{"namespace": nontestns, "name": "grant1"},
+			"spec": map[string]interface{}{
+				"from": []interface{}{map[string]interface{}{"group": GatewayGroup, "kind": fromKind, "namespace": testns}},
+				"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service", "name": toName}},
+			},
+		}}
+	}
+	svc2 := GatewayRef{Group: "", Kind: "Service", Namespace: nontestns, Name: "svc2"}
+
+	testCases := []struct {
+		name     string
+		grants   []unstructured.Unstructured
+		ref      GatewayRef
+		expected bool
+	}{
+		{
+			name:     "Reference in the same namespace",
+			grants:   []unstructured.Unstructured{},
+			ref:      GatewayRef{Group: "", Kind: "Service", Namespace: testns, Name: "svc1"},
+			expected: true,
+		},
+		{
+			name:     "Reference to other namespace without grants",
+			grants:   []unstructured.Unstructured{},
+			ref:      svc2,
+			expected: false,
+		},
+		{
+			name:     "Reference to other namespace with grant for the name",
+			grants:   []unstructured.Unstructured{grant("HTTPRoute", "svc2")},
+			ref:      svc2,
+			expected: true,
+		},
+		{
+			name:     "Reference to other namespace with grant for all services",
+			grants:   []unstructured.Unstructured{grant("HTTPRoute", "")},
+			ref:      svc2,
+			expected: true,
+		},
+		{
+			name:     "Reference to other namespace with grant for other name",
+			grants:   []unstructured.Unstructured{grant("HTTPRoute", "svc3")},
+			ref:      svc2,
+			expected: false,
+		},
+		{
+			name:     "Reference to other namespace with grant for other kind",
+			grants:   []unstructured.Unstructured{grant("GRPCRoute", "svc2")},
+			ref:      svc2,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ress := []*Resources{
+			{Namespace: testns},
+			{Namespace: nontestns, ReferenceGrants: &unstructured.UnstructuredList{Items: tc.grants}},
+		}
+		for _, res := range ress {
+			res.ensureLists()
+		}
+
+		granted := ReferenceGranted(ress, GatewayGroup, "HTTPRoute", testns, tc.ref)
+		if granted != tc.expected {
+			t.Fatalf("[%s] ReferenceGranted doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, granted)
+		}
+	}
+}
+
+func TestCollectGateway(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1"}}
+
+	testCases := []struct {
+		name     string
+		objs     []runtime.Object
+		expected []string
+	}{
+		{
+			name:     "Gateway API isn't served",
+			objs:     []runtime.Object{pod},
+			expected: []string{},
+		},
+		{
+			name:     "Gateway API is served",
+			objs:     []runtime.Object{pod, testGateway, testRoute},
+			expected: []string{"gw1", "route1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		res, err := NewCollector(NewOfflineClientset(tc.objs), Options{DynamicClient: NewOfflineDynamicClient(tc.objs)}).Collect(context.TODO(), testns)
+		if err != nil {
+			t.Fatalf("[%s] Collect failed: %v", tc.name, err)
+		}
+		names := append(res.GetResourceNames("gtw"), res.GetResourceNames("httproute")...)
+		names = append(names, res.GetResourceNames("grpcroute")...)
+		if strings.Join(tc.expected, ",") != strings.Join(names, ",") {
+			t.Fatalf("[%s] Collect doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, names)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+
+	autov1 "k8s.io/api/autoscaling/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// listHpas returns the hpas in the namespace as autoscaling/v2
+// It falls back to autoscaling/v1, if the k8s cluster doesn't serve autoscaling/v2,
+// and v1 is returned true in that case.
+func listHpas(ctx context.Context, cs kubernetes.Interface, namespace string, listOpts metav1.ListOptions) (hpas *autov2.HorizontalPodAutoscalerList, v1 bool, err error) {
+	hpas = &autov2.HorizontalPodAutoscalerList{}
+	err = listAll(ctx, hpas, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return cs.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, opts)
+	})
+	if err == nil || !apierrors.IsNotFound(err) {
+		return hpas, false, err
+	}
+
+	v1Hpas := &autov1.HorizontalPodAutoscalerList{}
+	err = listAll(ctx, v1Hpas, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return cs.AutoscalingV1().HorizontalPodAutoscalers(namespace).List(ctx, opts)
+	})
+	if err != nil {
+		return nil, true, err
+	}
+	hpas = &autov2.HorizontalPodAutoscalerList{ListMeta: v1Hpas.ListMeta}
+	for i := range v1Hpas.Items {
+		hpas.Items = append(hpas.Items, hpaFromV1(&v1Hpas.Items[i]))
+	}
+	return hpas, true, nil
+}
+
+// hpaFromV1 returns the autoscaling/v2 hpa for the autoscaling/v1 hpa
+// Target CPU utilization of v1 is converted to the resource metric for cpu.
+func hpaFromV1(hpa *autov1.HorizontalPodAutoscaler) autov2.HorizontalPodAutoscaler {
+	ret := autov2.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		ObjectMeta: hpa.ObjectMeta,
+		Spec: autov2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autov2.CrossVersionObjectReference{
+				Kind:       hpa.Spec.ScaleTargetRef.Kind,
+				Name:       hpa.Spec.ScaleTargetRef.Name,
+				APIVersion: hpa.Spec.ScaleTargetRef.APIVersion,
+			},
+			MinReplicas: hpa.Spec.MinReplicas,
+			MaxReplicas: hpa.Spec.MaxReplicas,
+		},
+		Status: autov2.HorizontalPodAutoscalerStatus{
+			ObservedGeneration: hpa.Status.ObservedGeneration,
+			LastScaleTime:      hpa.Status.LastScaleTime,
+			CurrentReplicas:    hpa.Status.CurrentReplicas,
+			DesiredReplicas:    hpa.Status.DesiredReplicas,
+		},
+	}
+
+	if hpa.Spec.TargetCPUUtilizationPercentage != nil {
+		ret.Spec.Metrics = []autov2.MetricSpec{{
+			Type: autov2.ResourceMetricSourceType,
+			Resource: &autov2.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: autov2.MetricTarget{Type: autov2.UtilizationMetricType, AverageUtilization: hpa.Spec.TargetCPUUtilizationPercentage},
+			},
+		}}
+	}
+	if hpa.Status.CurrentCPUUtilizationPercentage != nil {
+		ret.Status.CurrentMetrics = []autov2.MetricStatus{{
+			Type: autov2.ResourceMetricSourceType,
+			Resource: &autov2.ResourceMetricStatus{
+				Name:    corev1.ResourceCPU,
+				Current: autov2.MetricValueStatus{AverageUtilization: hpa.Status.CurrentCPUUtilizationPercentage},
+			},
+		}}
+	}
+	return ret
+}
+
+// fillHpas stores hpas got from an informer for autoscaling/v1 or autoscaling/v2 to res
+func fillHpas(res *Resources, objs []interface{}) {
+	res.Hpas = &autov2.HorizontalPodAutoscalerList{}
+	for _, o := range objs {
+		switch hpa := o.(type) {
+		case *autov1.HorizontalPodAutoscaler:
+			res.Hpas.Items = append(res.Hpas.Items, hpaFromV1(hpa))
+		case *autov2.HorizontalPodAutoscaler:
+			res.Hpas.Items = append(res.Hpas.Items, *hpa)
+		}
+	}
+}
+
+// hpaToV2 returns obj converted to autoscaling/v2, if it is an hpa of the older versions
+// It's for the objects served without k8s cluster, which doesn't convert the versions.
+func hpaToV2(obj runtime.Object) (runtime.Object, error) {
+	switch hpa := obj.(type) {
+	case *autov1.HorizontalPodAutoscaler:
+		v2 := hpaFromV1(hpa)
+		return &v2, nil
+	case *autov2beta2.HorizontalPodAutoscaler:
+		// autoscaling/v2beta2 has the same fields as autoscaling/v2
+		b, err := json.Marshal(hpa)
+		if err != nil {
+			return nil, err
+		}
+		v2 := &autov2.HorizontalPodAutoscaler{}
+		if err := json.Unmarshal(b, v2); err != nil {
+			return nil, err
+		}
+		v2.APIVersion = "autoscaling/v2"
+		return v2, nil
+	}
+	return obj, nil
+}
+
+// CurrentMetric returns the current value of the metric in the status of the hpa
+// It returns nil if the status doesn't have the metric yet.
+func CurrentMetric(hpa *autov2.HorizontalPodAutoscaler, metric *autov2.MetricSpec) *autov2.MetricValueStatus {
+	for i := range hpa.Status.CurrentMetrics {
+		status := &hpa.Status.CurrentMetrics[i]
+		if status.Type != metric.Type {
+			continue
+		}
+		switch metric.Type {
+		case autov2.ResourceMetricSourceType:
+			if status.Resource != nil && metric.Resource != nil && status.Resource.Name == metric.Resource.Name {
+				return &status.Resource.Current
+			}
+		case autov2.ContainerResourceMetricSourceType:
+			if status.ContainerResource != nil && metric.ContainerResource != nil &&
+				status.ContainerResource.Name == metric.ContainerResource.Name && status.ContainerResource.Container == metric.ContainerResource.Container {
+				return &status.ContainerResource.Current
+			}
+		case autov2.PodsMetricSourceType:
+			if status.Pods != nil && metric.Pods != nil && status.Pods.Metric.Name == metric.Pods.Metric.Name {
+				return &status.Pods.Current
+			}
+		case autov2.ObjectMetricSourceType:
+			if status.Object != nil && metric.Object != nil && status.Object.Metric.Name == metric.Object.Metric.Name &&
+				status.Object.DescribedObject == metric.Object.DescribedObject {
+				return &status.Object.Current
+			}
+		case autov2.ExternalMetricSourceType:
+			if status.External != nil && metric.External != nil && status.External.Metric.Name == metric.External.Metric.Name {
+				return &status.External.Current
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	autov1 "k8s.io/api/autoscaling/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCollectHpaV1Fallback(t *testing.T) {
+	target := int32(50)
+	current := int32(45)
+	cs := fake.NewSimpleClientset(&autov1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "hpa1"},
+		Spec: autov1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef:                 autov1.CrossVersionObjectReference{Kind: "Deployment", Name: "deploy1", APIVersion: "apps/v1"},
+			MaxReplicas:                    5,
+			TargetCPUUtilizationPercentage: &target,
+		},
+		Status: autov1.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 3, CurrentCPUUtilizationPercentage: &current},
+	})
+	// autoscaling/v2 isn't served, like k8s clusters older than 1.23
+	cs.PrependReactor("list", "horizontalpodautoscalers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Version != "v2" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+
+	res, err := NewCollector(cs, Options{}).Collect(context.TODO(), testns)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if !res.hpaV1 || len(res.Hpas.Items) != 1 {
+		t.Fatalf("Collect doesn't return hpas through autoscaling/v1, returned: %v", res.Hpas.Items)
+	}
+
+	hpa := &res.Hpas.Items[0]
+	if hpa.Spec.ScaleTargetRef.Name != "deploy1" || hpa.Spec.MaxReplicas != 5 || hpa.Status.DesiredReplicas != 3 {
+		t.Fatalf("hpa isn't converted as expected, returned: %v", hpa)
+	}
+	if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Resource.Name != corev1.ResourceCPU || *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != target {
+		t.Fatalf("target cpu utilization isn't converted to metric, returned: %v", hpa.Spec.Metrics)
+	}
+	cur := CurrentMetric(hpa, &hpa.Spec.Metrics[0])
+	if cur == nil || *cur.AverageUtilization != current {
+		t.Fatalf("CurrentMetric doesn't return expected, expected:%v, returned:%v", current, cur)
+	}
+}
+
+func TestCurrentMetric(t *testing.T) {
+	utilization := int32(45)
+	hpa := &autov2.HorizontalPodAutoscaler{Status: autov2.HorizontalPodAutoscalerStatus{CurrentMetrics: []autov2.MetricStatus{
+		{Type: autov2.ResourceMetricSourceType, Resource: &autov2.ResourceMetricStatus{
+			Name: corev1.ResourceCPU, Current: autov2.MetricValueStatus{AverageUtilization: &utilization}}},
+		{Type: autov2.PodsMetricSourceType, Pods: &autov2.PodsMetricStatus{
+			Metric: autov2.MetricIdentifier{Name: "requests"}, Current: autov2.MetricValueStatus{AverageUtilization: &utilization}}},
+	}}}
+
+	testCases := []struct {
+		name     string
+		metric   autov2.MetricSpec
+		expected bool
+	}{
+		{
+			name:     "Resource metric with status",
+			metric:   autov2.MetricSpec{Type: autov2.ResourceMetricSourceType, Resource: &autov2.ResourceMetricSource{Name: corev1.ResourceCPU}},
+			expected: true,
+		},
+		{
+			name:     "Resource metric without status",
+			metric:   autov2.MetricSpec{Type: autov2.ResourceMetricSourceType, Resource: &autov2.ResourceMetricSource{Name: corev1.ResourceMemory}},
+			expected: false,
+		},
+		{
+			name:     "Pods metric with status",
+			metric:   autov2.MetricSpec{Type: autov2.PodsMetricSourceType, Pods: &autov2.PodsMetricSource{Metric: autov2.MetricIdentifier{Name: "requests"}}},
+			expected: true,
+		},
+		{
+			name:     "External metric with the same name as pods metric",
+			metric:   autov2.MetricSpec{Type: autov2.ExternalMetricSourceType, External: &autov2.ExternalMetricSource{Metric: autov2.MetricIdentifier{Name: "requests"}}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cur := CurrentMetric(hpa, &tc.metric)
+		if (cur != nil) != tc.expected {
+			t.Fatalf("[%s] CurrentMetric doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, cur)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"strings"
+)
+
+const (
+	// defaultRegistry is the registry for the images without registry, like nginx
+	defaultRegistry = "docker.io"
+	// defaultTag is the tag for the images without tag and digest
+	defaultTag = "latest"
+	// shortDigestLen is the length of the digest shown as the version, like sha256:0123456789ab
+	shortDigestLen = len("sha256:") + 12
+)
+
+// Image represents a reference to a container image, like registry.k8s.io/pause:3.9
+type Image struct {
+	// Registry is the host of the registry, like registry.k8s.io. docker.io is used if it isn't specified.
+	Registry string
+	// Repository is the path of the image in the registry, like library/nginx
+	Repository string
+	// Tag is the tag of the image. latest is used if neither tag nor digest is specified.
+	Tag string
+	// Digest is the digest of the image, like sha256:0123...
+	Digest string
+}
+
+// ParseImage returns the Image for the reference, in the same way as docker does
+// The first component is the registry if it looks like a host, like localhost:5000 or gcr.io.
+func ParseImage(ref string) Image {
+	img := Image{}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		img.Digest = ref[i+1:]
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i >= 0 && !strings.Contains(ref[i:], "/") {
+		img.Tag = ref[i+1:]
+		ref = ref[:i]
+	}
+	if img.Tag == "" && img.Digest == "" {
+		img.Tag = defaultTag
+	}
+
+	img.Registry = defaultRegistry
+	if i := strings.Index(ref, "/"); i >= 0 && (strings.ContainsAny(ref[:i], ".:") || ref[:i] == "localhost") {
+		img.Registry = ref[:i]
+		ref = ref[i+1:]
+	}
+	if img.Registry == defaultRegistry && !strings.Contains(ref, "/") {
+		ref = "library/" + ref
+	}
+	img.Repository = ref
+	return img
+}
+
+// Name returns the last component of the repository, like nginx for library/nginx
+func (img Image) Name() string {
+	return img.Repository[strings.LastIndex(img.Repository, "/")+1:]
+}
+
+// Version returns the tag, or the short digest if the image is referred only by digest
+func (img Image) Version() string {
+	if img.Tag != "" {
+		return img.Tag
+	}
+	return ShortDigest(img.Digest)
+}
+
+// ShortDigest returns the digest shortened like sha256:0123456789ab
+func ShortDigest(digest string) string {
+	if len(digest) > shortDigestLen {
+		return digest[:shortDigestLen]
+	}
+	return digest
+}
+
+// Short returns the name and the version, like nginx:1.25
+func (img Image) Short() string {
+	if img.Tag == "" {
+		return img.Name() + "@" + img.Version()
+	}
+	return img.Name() + ":" + img.Version()
+}
+
+// String returns the full reference of the image, like docker.io/library/nginx:latest
+func (img Image) String() string {
+	ref := img.Registry + "/" + img.Repository
+	if img.Tag != "" {
+		ref += ":" + img.Tag
+	}
+	if img.Digest != "" {
+		ref += "@" + img.Digest
+	}
+	return ref
+}
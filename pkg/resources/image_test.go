@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"testing"
+)
+
+func TestParseImage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ref           string
+		expected      Image
+		expectedShort string
+	}{
+		{
+			name:          "Official image without tag",
+			ref:           "nginx",
+			expected:      Image{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+			expectedShort: "nginx:latest",
+		},
+		{
+			name:          "Image on docker hub with tag",
+			ref:           "istio/proxyv2:1.20.0",
+			expected:      Image{Registry: "docker.io", Repository: "istio/proxyv2", Tag: "1.20.0"},
+			expectedShort: "proxyv2:1.20.0",
+		},
+		{
+			name:          "Image on registry with port",
+			ref:           "localhost:5000/team/app:v2",
+			expected:      Image{Registry: "localhost:5000", Repository: "team/app", Tag: "v2"},
+			expectedShort: "app:v2",
+		},
+		{
+			name:          "Image with tag and digest",
+			ref:           "registry.k8s.io/pause:3.9@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097",
+			expected:      Image{Registry: "registry.k8s.io", Repository: "pause", Tag: "3.9", Digest: "sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097"},
+			expectedShort: "pause:3.9",
+		},
+		{
+			name:          "Image only with digest",
+			ref:           "gcr.io/distroless/static@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097",
+			expected:      Image{Registry: "gcr.io", Repository: "distroless/static", Digest: "sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097"},
+			expectedShort: "static@sha256:7031c1b28338",
+		},
+	}
+
+	for _, tc := range testCases {
+		img := ParseImage(tc.ref)
+		if img != tc.expected {
+			t.Fatalf("[%s] ParseImage doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, img)
+		}
+		if img.Short() != tc.expectedShort {
+			t.Fatalf("[%s] Short doesn't return expected, expected:%v, returned:%v", tc.name, tc.expectedShort, img.Short())
+		}
+	}
+}
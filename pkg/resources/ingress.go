@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// ingressClassAnnotation is the deprecated annotation for the ingress class, used before spec.ingressClassName
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+)
+
+// IngressClassName returns the name of the IngressClass of the ingress, or "" if it isn't set
+// spec.ingressClassName is preferred to the deprecated annotation.
+func IngressClassName(ing *netv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil && *ing.Spec.IngressClassName != "" {
+		return *ing.Spec.IngressClassName
+	}
+	return ing.Annotations[ingressClassAnnotation]
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"testing"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIngressClassName(t *testing.T) {
+	nginx := "nginx"
+
+	testCases := []struct {
+		name     string
+		ing      *netv1.Ingress
+		expected string
+	}{
+		{
+			name:     "No class",
+			ing:      &netv1.Ingress{},
+			expected: "",
+		},
+		{
+			name:     "ingressClassName",
+			ing:      &netv1.Ingress{Spec: netv1.IngressSpec{IngressClassName: &nginx}},
+			expected: "nginx",
+		},
+		{
+			name:     "Annotation",
+			ing:      &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ingressClassAnnotation: "traefik"}}},
+			expected: "traefik",
+		},
+		{
+			name: "ingressClassName is preferred to annotation",
+			ing: &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ingressClassAnnotation: "traefik"}},
+				Spec: netv1.IngressSpec{IngressClassName: &nginx}},
+			expected: "nginx",
+		},
+	}
+
+	for _, tc := range testCases {
+		if class := IngressClassName(tc.ing); class != tc.expected {
+			t.Fatalf("[%s] IngressClassName doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, class)
+		}
+	}
+}
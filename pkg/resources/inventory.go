@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Workload represents a workload that runs pods from its pod template, like deploy/my-deploy
+type Workload struct {
+	Namespace string
+	Kind      string
+	Name      string
+}
+
+// ImageUsage represents an image used by the pod templates of workloads
+type ImageUsage struct {
+	Image     Image
+	Workloads []Workload
+	// Digests are the digests of the image that the pods are running, from the imageIDs of their containers
+	Digests []string
+	// OtherVersions are the versions of the same repository used by other workloads
+	OtherVersions []string
+}
+
+// Drifted returns whether the versions of the image drift, which means the same repository is used
+// at different versions, or the pods are running different digests for the same tag, like latest
+func (u *ImageUsage) Drifted() bool {
+	return len(u.OtherVersions) > 0 || len(u.Digests) > 1
+}
+
+// ImageInventory returns the images used by the pod templates of deploy, sts, ds and cronjob in ress,
+// sorted by registry, repository and version. Digests come from the pods in ress that use the images.
+func ImageInventory(ress []*Resources) []*ImageUsage {
+	usages := map[string]*ImageUsage{}
+	add := func(w Workload, spec *corev1.PodSpec) {
+		for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+			img := ParseImage(c.Image)
+			u, ok := usages[img.String()]
+			if !ok {
+				u = &ImageUsage{Image: img}
+				usages[img.String()] = u
+			}
+			if len(u.Workloads) == 0 || u.Workloads[len(u.Workloads)-1] != w {
+				u.Workloads = append(u.Workloads, w)
+			}
+		}
+	}
+	for _, res := range ress {
+		ns := res.Namespace
+		for _, o := range res.Deploys.Items {
+			add(Workload{ns, "deploy", o.Name}, &o.Spec.Template.Spec)
+		}
+		for _, o := range res.Stss.Items {
+			add(Workload{ns, "sts", o.Name}, &o.Spec.Template.Spec)
+		}
+		for _, o := range res.Dss.Items {
+			add(Workload{ns, "ds", o.Name}, &o.Spec.Template.Spec)
+		}
+		for _, o := range res.CronJobs.Items {
+			add(Workload{ns, "cronjob", o.Name}, &o.Spec.JobTemplate.Spec.Template.Spec)
+		}
+	}
+
+	// digests that the pods are running
+	for _, res := range ress {
+		for _, pod := range res.Pods.Items {
+			statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+			for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+				u, ok := usages[ParseImage(c.Image).String()]
+				if !ok {
+					continue
+				}
+				for _, status := range statuses {
+					if digest := imageDigest(status.ImageID); status.Name == c.Name && digest != "" && !contains(u.Digests, digest) {
+						u.Digests = append(u.Digests, digest)
+					}
+				}
+			}
+		}
+	}
+
+	sorted := []*ImageUsage{}
+	for _, u := range usages {
+		sort.Strings(u.Digests)
+		sorted = append(sorted, u)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Image.String() < sorted[j].Image.String() })
+
+	// versions of the same repository
+	for _, u := range sorted {
+		for _, other := range sorted {
+			if u != other && u.Image.Registry == other.Image.Registry && u.Image.Repository == other.Image.Repository &&
+				u.Image.Version() != other.Image.Version() && !contains(u.OtherVersions, other.Image.Version()) {
+				u.OtherVersions = append(u.OtherVersions, other.Image.Version())
+			}
+		}
+	}
+	return sorted
+}
+
+// imageDigest returns the digest in the imageID of a container status, like sha256:0123...
+// imageIDs are like docker-pullable://nginx@sha256:0123..., or only the id of the image, like sha256:4567...,
+// for local images. The id isn't a digest of the repository, so empty is returned for it.
+func imageDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return ""
+}
+
+// contains returns whether list contains s
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImageInventory(t *testing.T) {
+	spec := func(images ...string) corev1.PodTemplateSpec {
+		tmpl := corev1.PodTemplateSpec{}
+		for i, image := range images {
+			tmpl.Spec.Containers = append(tmpl.Spec.Containers, corev1.Container{Name: fmt.Sprintf("c%d", i), Image: image})
+		}
+		return tmpl
+	}
+	res := &Resources{Namespace: testns}
+	res.ensureLists()
+	res.Deploys.Items = []appsv1.Deployment{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"}, Spec: appsv1.DeploymentSpec{Template: spec("nginx:1.25", "istio/proxyv2:1.20.0")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "api"}, Spec: appsv1.DeploymentSpec{Template: spec("docker.io/library/nginx:1.24", "istio/proxyv2:1.20.0")}},
+	}
+	res.Stss.Items = []appsv1.StatefulSet{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db"}, Spec: appsv1.StatefulSetSpec{Template: spec("postgres:16")}},
+	}
+	res.CronJobs.Items = []batchv1.CronJob{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "backup"}, Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{
+			Spec: batchv1.JobSpec{Template: spec("postgres:16", "registry.example.com/tools/backup")}}}},
+	}
+	res.Pods.Items = []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db-0"}, Spec: spec("postgres:16").Spec,
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "c0", ImageID: "docker-pullable://postgres@sha256:aaa"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "backup-1"}, Spec: spec("postgres:16").Spec,
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "c0", ImageID: "docker.io/library/postgres@sha256:bbb"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web-1"}, Spec: spec("nginx:1.25").Spec,
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "c0", ImageID: "docker-pullable://nginx@sha256:ccc"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web-2"}, Spec: spec("nginx:1.25").Spec,
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "c0", ImageID: "sha256:ddd"}}}},
+	}
+
+	expected := []string{
+		"docker.io/istio/proxyv2:1.20.0 workloads:[deploy/web deploy/api] digests:[] others:[] drifted:false",
+		"docker.io/library/nginx:1.24 workloads:[deploy/api] digests:[] others:[1.25] drifted:true",
+		"docker.io/library/nginx:1.25 workloads:[deploy/web] digests:[sha256:ccc] others:[1.24] drifted:true",
+		"docker.io/library/postgres:16 workloads:[sts/db cronjob/backup] digests:[sha256:aaa sha256:bbb] others:[] drifted:true",
+		"registry.example.com/tools/backup:latest workloads:[cronjob/backup] digests:[] others:[] drifted:false",
+	}
+	returned := []string{}
+	for _, u := range ImageInventory([]*Resources{res}) {
+		workloads := []string{}
+		for _, w := range u.Workloads {
+			workloads = append(workloads, w.Kind+"/"+w.Name)
+		}
+		returned = append(returned, fmt.Sprintf("%s workloads:%v digests:%v others:%v drifted:%v", u.Image, workloads, u.Digests, u.OtherVersions, u.Drifted()))
+	}
+	if strings.Join(expected, "\n") != strings.Join(returned, "\n") {
+		t.Fatalf("ImageInventory doesn't return expected, expected:\n%s\nreturned:\n%s", strings.Join(expected, "\n"), strings.Join(returned, "\n"))
+	}
+}
+
+func TestImageDigest(t *testing.T) {
+	testCases := []struct {
+		imageID  string
+		expected string
+	}{
+		{imageID: "docker-pullable://nginx@sha256:0123", expected: "sha256:0123"},
+		{imageID: "docker.io/library/nginx@sha256:0123", expected: "sha256:0123"},
+		// Only the id of a local image
+		{imageID: "sha256:4567", expected: ""},
+		{imageID: "", expected: ""},
+	}
+	for _, tc := range testCases {
+		if digest := imageDigest(tc.imageID); digest != tc.expected {
+			t.Fatalf("[%s] imageDigest doesn't return expected, expected:%q, returned:%q", tc.imageID, tc.expected, digest)
+		}
+	}
+}
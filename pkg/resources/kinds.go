@@ -0,0 +1,565 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// builtinKinds are the kinds built in this tool, which are registered on init.
+// Kinds are grouped to the ranks by the same level of abstraction, from hpa and cronjob at the top
+// to ingress and gateway at the bottom. They are listed in the order of the List calls,
+// and kinds in the same rank are drawn in this order.
+var builtinKinds = []Kind{
+	{
+		Name:    "svc",
+		Aliases: []string{"service"},
+		Rank:    7,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Svcs = &corev1.ServiceList{}
+			return listAll(ctx, res.Svcs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.CoreV1().Services(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Svcs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Core().V1().Services().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Svcs = &corev1.ServiceList{}
+			fillList(res.Svcs, objs)
+		},
+	},
+	{
+		Name:    "pvc",
+		Aliases: []string{"persistentvolumeclaim"},
+		Rank:    4,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Pvcs = &corev1.PersistentVolumeClaimList{}
+			return listAll(ctx, res.Pvcs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.CoreV1().PersistentVolumeClaims(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Pvcs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Core().V1().PersistentVolumeClaims().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Pvcs = &corev1.PersistentVolumeClaimList{}
+			fillList(res.Pvcs, objs)
+		},
+	},
+	{
+		Name:    "cm",
+		Aliases: []string{"configmap"},
+		Rank:    5,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Cms = &corev1.ConfigMapList{}
+			return listAll(ctx, res.Cms, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.CoreV1().ConfigMaps(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Cms) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Core().V1().ConfigMaps().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Cms = &corev1.ConfigMapList{}
+			fillList(res.Cms, objs)
+		},
+	},
+	{
+		Name:  "secret",
+		Rank:  5,
+		List:  listSecrets,
+		Names: func(res *Resources) []string { return listNames(res.Secrets) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			if f.Metadata != nil {
+				return f.Metadata.ForResource(secretsResource).Informer(), nil
+			}
+			return f.Filtered.Core().V1().Secrets().Informer(), nil
+		},
+		Fill: fillSecrets,
+	},
+	{
+		Name:    "sa",
+		Aliases: []string{"serviceaccount"},
+		Rank:    5,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Sas = &corev1.ServiceAccountList{}
+			return listAll(ctx, res.Sas, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.CoreV1().ServiceAccounts(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Sas) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Core().V1().ServiceAccounts().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Sas = &corev1.ServiceAccountList{}
+			fillList(res.Sas, objs)
+		},
+	},
+	{
+		Name:    "rb",
+		Aliases: []string{"rolebinding"},
+		Rank:    6,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Rbs = &rbacv1.RoleBindingList{}
+			return listAll(ctx, res.Rbs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.RbacV1().RoleBindings(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Rbs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Rbac().V1().RoleBindings().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Rbs = &rbacv1.RoleBindingList{}
+			fillList(res.Rbs, objs)
+		},
+	},
+	{
+		Name: "role",
+		Rank: 6,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Roles = &rbacv1.RoleList{}
+			return listAll(ctx, res.Roles, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.RbacV1().Roles(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Roles) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Rbac().V1().Roles().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Roles = &rbacv1.RoleList{}
+			fillList(res.Roles, objs)
+		},
+	},
+	{
+		Name:    "pod",
+		Aliases: []string{"po"},
+		Rank:    3,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Pods = &corev1.PodList{}
+			return listAll(ctx, res.Pods, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.CoreV1().Pods(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Pods) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Core().V1().Pods().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Pods = &corev1.PodList{}
+			fillList(res.Pods, objs)
+		},
+	},
+	{
+		Name:    "sts",
+		Aliases: []string{"statefulset"},
+		Rank:    2,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Stss = &appsv1.StatefulSetList{}
+			return listAll(ctx, res.Stss, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.AppsV1().StatefulSets(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Stss) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Apps().V1().StatefulSets().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Stss = &appsv1.StatefulSetList{}
+			fillList(res.Stss, objs)
+		},
+	},
+	{
+		Name:    "ds",
+		Aliases: []string{"daemonset"},
+		Rank:    2,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Dss = &appsv1.DaemonSetList{}
+			return listAll(ctx, res.Dss, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.AppsV1().DaemonSets(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Dss) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Apps().V1().DaemonSets().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Dss = &appsv1.DaemonSetList{}
+			fillList(res.Dss, objs)
+		},
+	},
+	{
+		Name:    "rs",
+		Aliases: []string{"replicaset"},
+		Rank:    2,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Rss = &appsv1.ReplicaSetList{}
+			return listAll(ctx, res.Rss, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.AppsV1().ReplicaSets(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Rss) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Apps().V1().ReplicaSets().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Rss = &appsv1.ReplicaSetList{}
+			fillList(res.Rss, objs)
+		},
+	},
+	{
+		Name:    "deploy",
+		Aliases: []string{"deployment"},
+		Rank:    1,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Deploys = &appsv1.DeploymentList{}
+			return listAll(ctx, res.Deploys, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.AppsV1().Deployments(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Deploys) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Apps().V1().Deployments().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Deploys = &appsv1.DeploymentList{}
+			fillList(res.Deploys, objs)
+		},
+	},
+	{
+		Name: "job",
+		Rank: 1,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Jobs = &batchv1.JobList{}
+			return listAll(ctx, res.Jobs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.BatchV1().Jobs(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Jobs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Batch().V1().Jobs().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Jobs = &batchv1.JobList{}
+			fillList(res.Jobs, objs)
+		},
+	},
+	{
+		Name:    "hpa",
+		Aliases: []string{"horizontalpodautoscaler"},
+		Rank:    0,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) (err error) {
+			res.Hpas, res.hpaV1, err = listHpas(ctx, clients.Clientset, res.Namespace, listOpts)
+			return err
+		},
+		Names: func(res *Resources) []string { return listNames(res.Hpas) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			if res.hpaV1 {
+				return f.Filtered.Autoscaling().V1().HorizontalPodAutoscalers().Informer(), nil
+			}
+			return f.Filtered.Autoscaling().V2().HorizontalPodAutoscalers().Informer(), nil
+		},
+		Fill: fillHpas,
+	},
+	{
+		Name:    "cronjob",
+		Aliases: []string{"cj"},
+		Rank:    0,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.CronJobs = &batchv1.CronJobList{}
+			return listAll(ctx, res.CronJobs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.BatchV1().CronJobs(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.CronJobs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Batch().V1().CronJobs().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.CronJobs = &batchv1.CronJobList{}
+			fillList(res.CronJobs, objs)
+		},
+	},
+	{
+		Name:    "ing",
+		Aliases: []string{"ingress"},
+		Rank:    9,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Ingresses = &netv1.IngressList{}
+			return listAll(ctx, res.Ingresses, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.NetworkingV1().Ingresses(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Ingresses) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Networking().V1().Ingresses().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Ingresses = &netv1.IngressList{}
+			fillList(res.Ingresses, objs)
+		},
+	},
+	{
+		Name:    "netpol",
+		Aliases: []string{"networkpolicy"},
+		Rank:    7,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Netpols = &netv1.NetworkPolicyList{}
+			return listAll(ctx, res.Netpols, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.NetworkingV1().NetworkPolicies(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Netpols) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Networking().V1().NetworkPolicies().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Netpols = &netv1.NetworkPolicyList{}
+			fillList(res.Netpols, objs)
+		},
+	},
+	{
+		Name:     "gtw",
+		Aliases:  []string{"gateway"},
+		Rank:     9,
+		List:     gatewayListFunc("gtw"),
+		Names:    func(res *Resources) []string { return listNames(res.Gateways) },
+		Informer: gatewayInformerFunc("gtw"),
+		Fill:     gatewayFillFunc("gtw"),
+	},
+	{
+		Name:     "httproute",
+		Rank:     8,
+		List:     gatewayListFunc("httproute"),
+		Names:    func(res *Resources) []string { return listNames(res.HTTPRoutes) },
+		Informer: gatewayInformerFunc("httproute"),
+		Fill:     gatewayFillFunc("httproute"),
+	},
+	{
+		Name:     "grpcroute",
+		Rank:     8,
+		List:     gatewayListFunc("grpcroute"),
+		Names:    func(res *Resources) []string { return listNames(res.GRPCRoutes) },
+		Informer: gatewayInformerFunc("grpcroute"),
+		Fill:     gatewayFillFunc("grpcroute"),
+	},
+	{
+		Name:    "rc",
+		Aliases: []string{"replicationcontroller"},
+		Rank:    2,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Rcs = &corev1.ReplicationControllerList{}
+			return listAll(ctx, res.Rcs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.CoreV1().ReplicationControllers(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Rcs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Core().V1().ReplicationControllers().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Rcs = &corev1.ReplicationControllerList{}
+			fillList(res.Rcs, objs)
+		},
+	},
+	{
+		Name:    "pdb",
+		Aliases: []string{"poddisruptionbudget"},
+		Rank:    7,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			res.Pdbs = &policyv1.PodDisruptionBudgetList{}
+			return listAll(ctx, res.Pdbs, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.Clientset.PolicyV1().PodDisruptionBudgets(res.Namespace).List(ctx, opts)
+			})
+		},
+		Names: func(res *Resources) []string { return listNames(res.Pdbs) },
+		Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+			return f.Filtered.Policy().V1().PodDisruptionBudgets().Informer(), nil
+		},
+		Fill: func(res *Resources, objs []interface{}) {
+			res.Pdbs = &policyv1.PodDisruptionBudgetList{}
+			fillList(res.Pdbs, objs)
+		},
+	},
+}
+
+// auxiliaryKind is a kind that isn't drawn as nodes, but is needed to draw the other kinds,
+// like ResourceQuotas shown on the namespaces. Auxiliary kinds aren't registered.
+type auxiliaryKind struct {
+	Kind
+	// enabled returns whether the kind is collected with opts. The kind is always collected if it's nil.
+	enabled func(opts Options) bool
+}
+
+// auxiliaryKinds are the auxiliary kinds, which are collected and watched after the registered kinds
+var auxiliaryKinds = []auxiliaryKind{
+	{
+		// ReferenceGrants aren't drawn, but they are needed to draw the cross-namespace references of routes
+		Kind: Kind{
+			Name:     "referencegrant",
+			List:     gatewayListFunc("referencegrant"),
+			Informer: gatewayInformerFunc("referencegrant"),
+			Fill:     gatewayFillFunc("referencegrant"),
+		},
+	},
+	{
+		// ResourceQuotas and LimitRanges aren't filtered by the selectors, because they apply to the whole namespace
+		Kind: Kind{
+			Name: "quota",
+			List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+				res.ResourceQuotas = &corev1.ResourceQuotaList{}
+				return listAll(ctx, res.ResourceQuotas, metav1.ListOptions{Limit: listOpts.Limit}, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+					return clients.Clientset.CoreV1().ResourceQuotas(res.Namespace).List(ctx, opts)
+				})
+			},
+			Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+				return f.Unfiltered.Core().V1().ResourceQuotas().Informer(), nil
+			},
+			Fill: func(res *Resources, objs []interface{}) {
+				res.ResourceQuotas = &corev1.ResourceQuotaList{}
+				fillList(res.ResourceQuotas, objs)
+			},
+		},
+	},
+	{
+		Kind: Kind{
+			Name: "limitrange",
+			List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+				res.LimitRanges = &corev1.LimitRangeList{}
+				return listAll(ctx, res.LimitRanges, metav1.ListOptions{Limit: listOpts.Limit}, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+					return clients.Clientset.CoreV1().LimitRanges(res.Namespace).List(ctx, opts)
+				})
+			},
+			Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+				return f.Unfiltered.Core().V1().LimitRanges().Informer(), nil
+			},
+			Fill: func(res *Resources, objs []interface{}) {
+				res.LimitRanges = &corev1.LimitRangeList{}
+				fillList(res.LimitRanges, objs)
+			},
+		},
+	},
+	{
+		// EndpointSlices aren't filtered by the selectors, because they don't have the labels of services
+		Kind: Kind{
+			Name: "endpointslice",
+			List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+				res.EndpointSlices = &discoveryv1.EndpointSliceList{}
+				return listAll(ctx, res.EndpointSlices, metav1.ListOptions{Limit: listOpts.Limit}, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+					return clients.Clientset.DiscoveryV1().EndpointSlices(res.Namespace).List(ctx, opts)
+				})
+			},
+			Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+				return f.Unfiltered.Discovery().V1().EndpointSlices().Informer(), nil
+			},
+			Fill: func(res *Resources, objs []interface{}) {
+				res.EndpointSlices = &discoveryv1.EndpointSliceList{}
+				fillList(res.EndpointSlices, objs)
+			},
+		},
+		enabled: func(opts Options) bool { return opts.EndpointSlices },
+	},
+	{
+		// PersistentVolumes and StorageClasses are cluster-scoped, so they aren't filtered by the selectors
+		Kind: Kind{
+			Name: "pv",
+			List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+				pvs := &corev1.PersistentVolumeList{}
+				err := listAll(ctx, pvs, metav1.ListOptions{Limit: listOpts.Limit}, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+					return clients.Clientset.CoreV1().PersistentVolumes().List(ctx, opts)
+				})
+				pvs.Items = claimedPvs(pvs.Items, res.Namespace)
+				res.Pvs = pvs
+				return err
+			},
+			Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+				return f.Cluster.Core().V1().PersistentVolumes().Informer(), nil
+			},
+			Fill: func(res *Resources, objs []interface{}) {
+				res.Pvs = &corev1.PersistentVolumeList{}
+				fillList(res.Pvs, objs)
+				res.Pvs.Items = claimedPvs(res.Pvs.Items, res.Namespace)
+			},
+		},
+		enabled: func(opts Options) bool { return opts.Storage },
+	},
+	{
+		Kind: Kind{
+			Name: "storageclass",
+			List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+				res.StorageClasses = &storagev1.StorageClassList{}
+				return listAll(ctx, res.StorageClasses, metav1.ListOptions{Limit: listOpts.Limit}, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+					return clients.Clientset.StorageV1().StorageClasses().List(ctx, opts)
+				})
+			},
+			Informer: func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error) {
+				return f.Cluster.Storage().V1().StorageClasses().Informer(), nil
+			},
+			Fill: func(res *Resources, objs []interface{}) {
+				res.StorageClasses = &storagev1.StorageClassList{}
+				fillList(res.StorageClasses, objs)
+			},
+		},
+		enabled: func(opts Options) bool { return opts.Storage },
+	},
+}
+
+// enabledAuxiliaryKinds returns the auxiliary kinds that are collected with opts
+func enabledAuxiliaryKinds(opts Options) []Kind {
+	kinds := []Kind{}
+	for _, aux := range auxiliaryKinds {
+		if aux.enabled == nil || aux.enabled(opts) {
+			kinds = append(kinds, aux.Kind)
+		}
+	}
+	return kinds
+}
+
+func init() {
+	for _, kind := range builtinKinds {
+		if err := RegisterKind(kind); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// gatewayListFunc returns the function to list the kind of Gateway API through the dynamic client
+func gatewayListFunc(kind string) func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+	var gk gatewayKind
+	for _, k := range gatewayKinds {
+		if k.kind == kind {
+			gk = k
+		}
+	}
+	return func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) (err error) {
+		*res.gatewayList(kind), err = getGatewayObjects(ctx, clients.Clientset.Discovery(), clients.DynamicClient, res.Namespace, gk, listOpts)
+		return err
+	}
+}
+
+// isNamespaceName returns whether name is the name of namespace, which isn't registered
+// because namespaces are drawn as the clusters, not as the nodes.
+func isNamespaceName(name string) bool {
+	name = strings.ToLower(name)
+	return name == "ns" || name == "namespace"
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+)
+
+const (
+	// DefaultPageSize is the number of objects in a List response if Options.PageSize isn't specified
+	DefaultPageSize = 500
+)
+
+// listAll lists all objects into list, by calling listPage for each page.
+// The page size is taken from listOpts.Limit, and the following pages are got with the continue tokens.
+// The pager falls back to list all objects at once, if the continue token expires before the last page.
+// Fields that graph generation never reads are stripped from each page as soon as it's got,
+// so that the memory use is bounded by the objects that are kept, not by the responses.
+func listAll(ctx context.Context, list runtime.Object, listOpts metav1.ListOptions, listPage pager.ListPageFunc) error {
+	p := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		page, err := listPage(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return page, meta.EachListItem(page, stripUnusedFields)
+	})
+	p.PageSize = listOpts.Limit
+
+	all, _, err := p.List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	objs, err := meta.ExtractList(all)
+	if err != nil {
+		return err
+	}
+	return meta.SetList(list, objs)
+}
+
+// stripUnusedFields removes the fields of obj that graph generation never reads,
+// like managedFields and the last applied configuration.
+func stripUnusedFields(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	accessor.SetManagedFields(nil)
+	accessor.SetAnnotations(withoutLastAppliedConfig(accessor.GetAnnotations()))
+	return nil
+}
+
+// withoutLastAppliedConfig returns annotations without the last applied configuration.
+// Annotations are copied, not to modify the maps shared with informer caches.
+func withoutLastAppliedConfig(annotations map[string]string) map[string]string {
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; !ok {
+		return annotations
+	}
+	copied := map[string]string{}
+	for k, v := range annotations {
+		if k != corev1.LastAppliedConfigAnnotation {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+// stripTransform is stripUnusedFields as the transform of informers,
+// which strips objects before they are stored to the caches.
+func stripTransform(obj interface{}) (interface{}, error) {
+	o, ok := obj.(runtime.Object)
+	if !ok {
+		// ex) cache.DeletedFinalStateUnknown
+		return obj, nil
+	}
+	return o, stripUnusedFields(o)
+}
+
+// fillList stores objs, which are got from an informer, to list
+// Objects are already stripped by stripTransform.
+func fillList(list runtime.Object, objs []interface{}) {
+	items := make([]runtime.Object, 0, len(objs))
+	for _, obj := range objs {
+		items = append(items, obj.(runtime.Object))
+	}
+	_ = meta.SetList(list, items)
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// pagedPods returns the function to list the pods page by page, like k8s api server.
+// The continue token is the index of the first pod in the next page.
+// If expireAfter is positive, continue tokens expire after the pages.
+// Requests are recorded as the limits and the continue tokens, like 2/4.
+func pagedPods(pods []corev1.Pod, expireAfter int, requests *[]string) func(context.Context, metav1.ListOptions) (runtime.Object, error) {
+	pages := 0
+	return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		*requests = append(*requests, fmt.Sprintf("%d/%s", opts.Limit, opts.Continue))
+		start := 0
+		if opts.Continue != "" {
+			if expireAfter > 0 && pages >= expireAfter {
+				return nil, apierrors.NewResourceExpired("continue token expired")
+			}
+			start, _ = strconv.Atoi(opts.Continue)
+		}
+		pages++
+
+		list := &corev1.PodList{}
+		end := len(pods)
+		if opts.Limit > 0 && start+int(opts.Limit) < end {
+			end = start + int(opts.Limit)
+			list.Continue = strconv.Itoa(end)
+		}
+		list.Items = append(list.Items, pods[start:end]...)
+		return list, nil
+	}
+}
+
+func TestListAll(t *testing.T) {
+	pods := []corev1.Pod{}
+	for i := 1; i <= 5; i++ {
+		pods = append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: fmt.Sprintf("pod%d", i),
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			Annotations:   map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "note": "kept"}}})
+	}
+
+	testCases := []struct {
+		name             string
+		pageSize         int64
+		expireAfter      int
+		expectedRequests []string
+	}{
+		{
+			name:             "All pods in one page",
+			pageSize:         10,
+			expectedRequests: []string{"10/"},
+		},
+		{
+			name:             "Pods in multiple pages",
+			pageSize:         2,
+			expectedRequests: []string{"2/", "2/2", "2/4"},
+		},
+		{
+			// The pager lists all pods at once without the continue token, instead of the expired one
+			name:             "Continue token expires",
+			pageSize:         2,
+			expireAfter:      1,
+			expectedRequests: []string{"2/", "2/2", "0/"},
+		},
+	}
+
+	for _, tc := range testCases {
+		requests := []string{}
+		list := &corev1.PodList{}
+		err := listAll(context.TODO(), list, metav1.ListOptions{Limit: tc.pageSize}, pagedPods(pods, tc.expireAfter, &requests))
+		if err != nil {
+			t.Fatalf("[%s] listAll failed: %v", tc.name, err)
+		}
+
+		names := []string{}
+		for _, pod := range list.Items {
+			names = append(names, pod.Name)
+			if len(pod.ManagedFields) != 0 || pod.Annotations[corev1.LastAppliedConfigAnnotation] != "" || pod.Annotations["note"] != "kept" {
+				t.Fatalf("[%s] listAll doesn't strip unused fields as expected, returned: %v", tc.name, pod.ObjectMeta)
+			}
+		}
+		if strings.Join(names, ",") != "pod1,pod2,pod3,pod4,pod5" {
+			t.Fatalf("[%s] listAll doesn't return expected, expected:%v, returned:%v", tc.name, "pod1,pod2,pod3,pod4,pod5", names)
+		}
+		if strings.Join(requests, ",") != strings.Join(tc.expectedRequests, ",") {
+			t.Fatalf("[%s] listAll doesn't request expected pages, expected:%v, returned:%v", tc.name, tc.expectedRequests, requests)
+		}
+	}
+
+	// Annotations shared with the original pods aren't modified
+	if pods[0].Annotations[corev1.LastAppliedConfigAnnotation] == "" {
+		t.Fatalf("listAll modifies the annotations of the original objects")
+	}
+}
@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var (
+	// manifestExts is the set of file extensions read from a directory
+	manifestExts = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+	// clusterScopedKinds is the set of the kinds built in k8s that don't belong to any namespace.
+	// Kinds that aren't in it, like the kinds of CRDs, are treated as namespaced.
+	clusterScopedKinds = map[schema.GroupKind]bool{
+		{Kind: "Namespace"}:                                                             true,
+		{Kind: "Node"}:                                                                  true,
+		{Kind: "PersistentVolume"}:                                                      true,
+		{Kind: "ComponentStatus"}:                                                       true,
+		{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+		{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+		{Group: "storage.k8s.io", Kind: "CSINode"}:                                      true,
+		{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+		{Group: "networking.k8s.io", Kind: "IngressClass"}:                              true,
+		{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+		{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+		{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+		{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+		{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+		{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+		{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                     true,
+		{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:     true,
+		{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+	}
+)
+
+// LoadManifests returns k8s objects read from the manifest files.
+// Each path can be a file, a directory, or "-" for stdin. Directories are
+// read recursively for .yaml, .yml and .json files. A file can contain
+// multiple documents and List documents, like the output of
+// `kubectl get -o yaml`. Namespaced objects without namespace are put
+// in defaultNamespace. Objects of the kinds that aren't built in client-go,
+// like CRD, are returned as *unstructured.Unstructured.
+func LoadManifests(paths []string, defaultNamespace string) ([]runtime.Object, error) {
+	objs := []runtime.Object{}
+	for _, path := range paths {
+		if path == "-" {
+			o, err := decodeManifests(os.Stdin, defaultNamespace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode manifests from stdin: %v", err)
+			}
+			objs = append(objs, o...)
+			continue
+		}
+
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// Only files with known extensions are read from directories,
+			// but files specified directly are always read.
+			if p != path && !manifestExts[strings.ToLower(filepath.Ext(p))] {
+				return nil
+			}
+
+			f, err := os.Open(filepath.Clean(p))
+			if err != nil {
+				return err
+			}
+			o, err := decodeManifests(f, defaultNamespace)
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to decode manifests in %q: %v", p, err)
+			}
+			objs = append(objs, o...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objs, nil
+}
+
+// decodeManifests returns k8s objects decoded from YAML or JSON documents in r
+func decodeManifests(r io.Reader, defaultNamespace string) ([]runtime.Object, error) {
+	objs := []runtime.Object{}
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		// Skip empty documents
+		if len(u.Object) == 0 {
+			continue
+		}
+
+		items := []unstructured.Unstructured{*u}
+		if u.IsList() {
+			list, err := u.ToList()
+			if err != nil {
+				return nil, err
+			}
+			items = list.Items
+		}
+
+		for i := range items {
+			obj, err := toTyped(&items[i], defaultNamespace)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping %s %q: %v\n", items[i].GetKind(), items[i].GetName(), err)
+				continue
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	return objs, nil
+}
+
+// toTyped converts u to the typed object registered in the client-go scheme
+// u is returned as it is, if the kind isn't registered in the scheme.
+func toTyped(u *unstructured.Unstructured, defaultNamespace string) (runtime.Object, error) {
+	if u.GetNamespace() == "" && !clusterScopedKinds[u.GroupVersionKind().GroupKind()] {
+		u.SetNamespace(defaultNamespace)
+	}
+
+	obj, err := scheme.Scheme.New(u.GroupVersionKind())
+	if runtime.IsNotRegisteredError(err) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// NewOfflineClientset returns a clientset that serves objs without k8s cluster.
+// Namespaces that objs belong to are also served, even if they aren't in objs.
+// Unstructured objects aren't served by the clientset, but their kinds are
+// served by its discovery client, to be got through NewOfflineDynamicClient.
+func NewOfflineClientset(objs []runtime.Object) kubernetes.Interface {
+	namespaces := map[string]bool{}
+	for _, obj := range objs {
+		if ns, ok := obj.(*corev1.Namespace); ok {
+			namespaces[ns.Name] = true
+		}
+	}
+
+	all := []runtime.Object{}
+	resourceLists := map[string]*metav1.APIResourceList{}
+	kinds := map[schema.GroupVersionKind]bool{}
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			// hpas are got through autoscaling/v2
+			o, err := hpaToV2(obj)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping hpa: %v\n", err)
+				continue
+			}
+			all = append(all, o)
+			continue
+		}
+
+		gvk := u.GroupVersionKind()
+		if kinds[gvk] {
+			continue
+		}
+		kinds[gvk] = true
+		gv := gvk.GroupVersion().String()
+		if _, ok := resourceLists[gv]; !ok {
+			resourceLists[gv] = &metav1.APIResourceList{GroupVersion: gv}
+		}
+		plural, singular := meta.UnsafeGuessKindToResource(gvk)
+		resourceLists[gv].APIResources = append(resourceLists[gv].APIResources, metav1.APIResource{
+			Name:         plural.Resource,
+			SingularName: singular.Resource,
+			Namespaced:   u.GetNamespace() != "",
+			Kind:         gvk.Kind,
+			Verbs:        metav1.Verbs{"get", "list"},
+		})
+	}
+
+	for _, obj := range objs {
+		o, ok := obj.(metav1.Object)
+		if !ok || o.GetNamespace() == "" || namespaces[o.GetNamespace()] {
+			continue
+		}
+		namespaces[o.GetNamespace()] = true
+		all = append(all, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: o.GetNamespace()}})
+	}
+
+	cs := fake.NewSimpleClientset(all...)
+	if dc, ok := cs.Discovery().(*fakediscovery.FakeDiscovery); ok {
+		for _, rl := range resourceLists {
+			dc.Resources = append(dc.Resources, rl)
+		}
+	}
+	return cs
+}
+
+// NewOfflineDynamicClient returns a dynamic client that serves unstructured
+// objects in objs without k8s cluster.
+func NewOfflineDynamicClient(objs []runtime.Object) dynamic.Interface {
+	us := []runtime.Object{}
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			us = append(us, u)
+		}
+	}
+
+	return dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), us...)
+}
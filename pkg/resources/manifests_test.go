@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	manifestsDir = filepath.Join("testdata", "manifests")
+)
+
+func TestLoadManifests(t *testing.T) {
+	testCases := []struct {
+		name      string
+		paths     []string
+		kind      string
+		namespace string
+		expected  []string
+	}{
+		{
+			name:      "Multiple documents in a file are read and namespace is defaulted",
+			paths:     []string{filepath.Join(manifestsDir, "deploy.yaml")},
+			kind:      "deploy",
+			namespace: testns,
+			expected:  []string{"deploy1"},
+		},
+		{
+			name:      "Directory is read recursively and List document is expanded",
+			paths:     []string{manifestsDir},
+			kind:      "pod",
+			namespace: testns,
+			expected:  []string{"pod1"},
+		},
+		{
+			name:      "Objects with namespace are kept in the namespace",
+			paths:     []string{manifestsDir},
+			kind:      "pod",
+			namespace: nontestns,
+			expected:  []string{"pod2"},
+		},
+		{
+			name:      "Hpas of older versions are served as autoscaling/v2",
+			paths:     []string{filepath.Join(manifestsDir, "hpa.yaml")},
+			kind:      "hpa",
+			namespace: testns,
+			expected:  []string{"hpa1", "hpa2", "hpa3"},
+		},
+		{
+			name:      "Namespace without objects has no resources",
+			paths:     []string{manifestsDir},
+			kind:      "svc",
+			namespace: nontestns,
+			expected:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		objs, err := LoadManifests(tc.paths, testns)
+		if err != nil {
+			t.Fatalf("[%s] LoadManifests failed: %v", tc.name, err)
+		}
+
+		res, err := NewResources(NewOfflineClientset(objs), tc.namespace)
+		if err != nil {
+			t.Fatalf("[%s] NewResources failed: %v", tc.name, err)
+		}
+
+		resNames := res.GetResourceNames(tc.kind)
+		if len(tc.expected) != len(resNames) {
+			t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, resNames)
+		}
+		for i := range tc.expected {
+			if tc.expected[i] != resNames[i] {
+				t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, resNames)
+			}
+		}
+	}
+}
+
+func TestNewOfflineClientset(t *testing.T) {
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+
+	cs := NewOfflineClientset(objs)
+	for _, ns := range []string{testns, nontestns} {
+		if _, err := cs.CoreV1().Namespaces().Get(context.TODO(), ns, metav1.GetOptions{}); err != nil {
+			t.Fatalf("namespace %q should be served, but got error: %v", ns, err)
+		}
+	}
+}
+
+func TestLoadClusterScopedManifests(t *testing.T) {
+	objs, err := LoadManifests([]string{filepath.Join(manifestsDir, "clusterrole.yaml")}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("LoadManifests doesn't return expected, expected:[clusterrole1], returned:%v", objs)
+	}
+	cr, ok := objs[0].(*rbacv1.ClusterRole)
+	if !ok || cr.Namespace != "" {
+		t.Fatalf("LoadManifests doesn't return cluster-scoped clusterrole1, returned:%v", objs[0])
+	}
+
+	// Cluster-scoped objects are served without namespace, like k8s cluster
+	if _, err := NewOfflineClientset(objs).RbacV1().ClusterRoles().Get(context.TODO(), "clusterrole1", metav1.GetOptions{}); err != nil {
+		t.Fatalf("clusterrole1 should be served, but got error: %v", err)
+	}
+}
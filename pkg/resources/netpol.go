@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// namespaceNameLabel is the label that k8s sets to all namespaces with their names
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// Traffic represents the traffic from a pod to another pod that network policies allow
+type Traffic struct {
+	FromNamespace string
+	From          string
+	ToNamespace   string
+	To            string
+}
+
+// podKey identifies a pod across namespaces
+type podKey struct {
+	namespace string
+	name      string
+}
+
+// podSet is the set of pods, where nil means all pods
+type podSet map[podKey]bool
+
+// keys returns the pods in the set, or all pods if the set means all pods
+func (s podSet) keys(all []podKey) []podKey {
+	if s == nil {
+		return all
+	}
+	keys := []podKey{}
+	for key := range s {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// podPair represents the traffic from a pod to another pod
+type podPair struct {
+	from podKey
+	to   podKey
+}
+
+// policy represents a network policy with the pods that it applies to and allows
+type policy struct {
+	ingress bool
+	egress  bool
+	// selected are the pods that the policy applies to
+	selected podSet
+	// ingressPeers and egressPeers are the pods that the policy allows
+	ingressPeers podSet
+	egressPeers  podSet
+}
+
+// SelectedPods returns the names of the pods that the network policy applies to
+func (r *Resources) SelectedPods(np *netv1.NetworkPolicy) []string {
+	names, err := r.PodIndex().Match(&np.Spec.PodSelector)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid podSelector in networkpolicy %s: %v\n", np.Name, err)
+		return []string{}
+	}
+	return names
+}
+
+// AllowedTraffic returns the traffic between the pods in ress that network policies allow.
+// Traffic between pods that aren't isolated by any network policy is omitted,
+// because it is always allowed. Ports and ipBlocks in the rules are ignored.
+func AllowedTraffic(ress []*Resources) []Traffic {
+	nsIndex := NewLabelIndex()
+	for _, res := range ress {
+		nsLabels := map[string]string{namespaceNameLabel: res.Namespace}
+		for k, v := range res.NamespaceLabels {
+			nsLabels[k] = v
+		}
+		nsIndex.Add(res.Namespace, nsLabels)
+	}
+
+	policies := []*policy{}
+	for _, res := range ress {
+		for i := range res.Netpols.Items {
+			policies = append(policies, newPolicy(ress, nsIndex, res, &res.Netpols.Items[i]))
+		}
+	}
+
+	pods := []podKey{}
+	positions := map[podKey]int{}
+	for _, res := range ress {
+		for _, pod := range res.Pods.Items {
+			key := podKey{namespace: res.Namespace, name: pod.Name}
+			positions[key] = len(pods)
+			pods = append(pods, key)
+		}
+	}
+
+	// Pairs are expanded only from the pods that the policies select, not from all pairs of pods
+	egressIsolated, ingressIsolated := podSet{}, podSet{}
+	egressAllowed, ingressAllowed := map[podPair]bool{}, map[podPair]bool{}
+	for _, p := range policies {
+		if p.egress {
+			for from := range p.selected {
+				egressIsolated[from] = true
+				for _, to := range p.egressPeers.keys(pods) {
+					egressAllowed[podPair{from: from, to: to}] = true
+				}
+			}
+		}
+		if p.ingress {
+			for to := range p.selected {
+				ingressIsolated[to] = true
+				for _, from := range p.ingressPeers.keys(pods) {
+					ingressAllowed[podPair{from: from, to: to}] = true
+				}
+			}
+		}
+	}
+
+	// Traffic from the egress isolated pods needs to be allowed by egress, and also by ingress if the destination
+	// is ingress isolated. Traffic from the other pods only needs to be allowed by ingress.
+	allowed := []podPair{}
+	for pair := range egressAllowed {
+		if !ingressIsolated[pair.to] || ingressAllowed[pair] {
+			allowed = append(allowed, pair)
+		}
+	}
+	for pair := range ingressAllowed {
+		if !egressIsolated[pair.from] {
+			allowed = append(allowed, pair)
+		}
+	}
+	sort.Slice(allowed, func(i, j int) bool {
+		if allowed[i].from != allowed[j].from {
+			return positions[allowed[i].from] < positions[allowed[j].from]
+		}
+		return positions[allowed[i].to] < positions[allowed[j].to]
+	})
+
+	traffic := []Traffic{}
+	for _, pair := range allowed {
+		if pair.from == pair.to {
+			continue
+		}
+		traffic = append(traffic, Traffic{FromNamespace: pair.from.namespace, From: pair.from.name, ToNamespace: pair.to.namespace, To: pair.to.name})
+	}
+
+	return traffic
+}
+
+// newPolicy returns the policy for the network policy in res
+// Policy types default to Ingress, and also Egress if it has egress rules.
+func newPolicy(ress []*Resources, nsIndex *LabelIndex, res *Resources, np *netv1.NetworkPolicy) *policy {
+	p := &policy{selected: podSet{}, ingressPeers: podSet{}, egressPeers: podSet{}}
+	if len(np.Spec.PolicyTypes) == 0 {
+		p.ingress = true
+		p.egress = len(np.Spec.Egress) > 0
+	}
+	for _, t := range np.Spec.PolicyTypes {
+		switch t {
+		case netv1.PolicyTypeIngress:
+			p.ingress = true
+		case netv1.PolicyTypeEgress:
+			p.egress = true
+		}
+	}
+
+	for _, name := range res.SelectedPods(np) {
+		p.selected[podKey{namespace: res.Namespace, name: name}] = true
+	}
+
+	for _, rule := range np.Spec.Ingress {
+		if len(rule.From) == 0 {
+			p.ingressPeers = nil
+			break
+		}
+		addPeers(p.ingressPeers, ress, nsIndex, res, np, rule.From)
+	}
+	for _, rule := range np.Spec.Egress {
+		if len(rule.To) == 0 {
+			p.egressPeers = nil
+			break
+		}
+		addPeers(p.egressPeers, ress, nsIndex, res, np, rule.To)
+	}
+
+	return p
+}
+
+// addPeers adds the pods in ress that the peers in the network policy in res match to set
+// Peers without namespaceSelector match the pods in the namespace of res.
+func addPeers(set podSet, ress []*Resources, nsIndex *LabelIndex, res *Resources, np *netv1.NetworkPolicy, peers []netv1.NetworkPolicyPeer) {
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			continue
+		}
+
+		namespaces := []string{res.Namespace}
+		if peer.NamespaceSelector != nil {
+			var err error
+			if namespaces, err = nsIndex.Match(peer.NamespaceSelector); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid namespaceSelector in networkpolicy %s: %v\n", np.Name, err)
+				continue
+			}
+		}
+
+		for _, ns := range namespaces {
+			for _, peerRes := range ress {
+				if peerRes.Namespace != ns {
+					continue
+				}
+				names := peerRes.GetResourceNames("pod")
+				if peer.PodSelector != nil {
+					var err error
+					if names, err = peerRes.PodIndex().Match(peer.PodSelector); err != nil {
+						fmt.Fprintf(os.Stderr, "Invalid podSelector in networkpolicy %s: %v\n", np.Name, err)
+						continue
+					}
+				}
+				for _, name := range names {
+					set[podKey{namespace: ns, name: name}] = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAllowedTraffic(t *testing.T) {
+	web := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web", Labels: map[string]string{"app": "web"}}}
+	db := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db", Labels: map[string]string{"app": "db"}}}
+	client := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: nontestns, Name: "client", Labels: map[string]string{"app": "client"}}}
+	denyAll := netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "deny-all"},
+		Spec: netv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{}}}
+
+	testCases := []struct {
+		name     string
+		netpols  []netv1.NetworkPolicy
+		expected []string
+	}{
+		{
+			name:     "No network policies",
+			netpols:  []netv1.NetworkPolicy{},
+			expected: []string{},
+		},
+		{
+			name:     "Ingress to all pods in testns is denied",
+			netpols:  []netv1.NetworkPolicy{denyAll},
+			expected: []string{},
+		},
+		{
+			name: "Ingress to db is allowed from web in the same namespace",
+			netpols: []netv1.NetworkPolicy{denyAll, {ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db"},
+				Spec: netv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					Ingress: []netv1.NetworkPolicyIngressRule{{From: []netv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+					}}},
+				}}},
+			expected: []string{"testns/web->testns/db"},
+		},
+		{
+			name: "Ingress to web is allowed from all pods in namespaces with team=a",
+			netpols: []netv1.NetworkPolicy{denyAll, {ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+				Spec: netv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Ingress: []netv1.NetworkPolicyIngressRule{{From: []netv1.NetworkPolicyPeer{
+						{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}},
+					}}},
+				}}},
+			expected: []string{"nontestns/client->testns/web"},
+		},
+		{
+			name: "Egress from web is allowed only to db by name of namespace",
+			netpols: []netv1.NetworkPolicy{{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+				Spec: netv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeEgress},
+					Egress: []netv1.NetworkPolicyEgressRule{{To: []netv1.NetworkPolicyPeer{{
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": testns}},
+						PodSelector:       &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"db"}}}},
+					}}}},
+				}}},
+			expected: []string{"testns/web->testns/db"},
+		},
+		{
+			name: "Egress from web to everywhere is allowed only to pods without ingress isolation",
+			netpols: []netv1.NetworkPolicy{denyAll, {ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"},
+				Spec: netv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeEgress},
+					Egress:      []netv1.NetworkPolicyEgressRule{{}},
+				}}},
+			expected: []string{"testns/web->nontestns/client"},
+		},
+		{
+			name: "Peers of network policy without namespace are in the namespace of the resources",
+			netpols: []netv1.NetworkPolicy{denyAll, {ObjectMeta: metav1.ObjectMeta{Name: "db"},
+				Spec: netv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					Ingress: []netv1.NetworkPolicyIngressRule{{From: []netv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+					}}},
+				}}},
+			expected: []string{"testns/web->testns/db"},
+		},
+		{
+			name: "Ingress to all pods in testns is allowed from everywhere",
+			netpols: []netv1.NetworkPolicy{{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "allow-all"},
+				Spec: netv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{},
+					Ingress:     []netv1.NetworkPolicyIngressRule{{}},
+				}}},
+			expected: []string{"testns/web->testns/db", "testns/db->testns/web", "nontestns/client->testns/web", "nontestns/client->testns/db"},
+		},
+	}
+
+	for _, tc := range testCases {
+		ress := []*Resources{
+			{Namespace: testns, Pods: &corev1.PodList{Items: []corev1.Pod{web, db}}, Netpols: &netv1.NetworkPolicyList{Items: tc.netpols}},
+			{Namespace: nontestns, NamespaceLabels: map[string]string{"team": "a"}, Pods: &corev1.PodList{Items: []corev1.Pod{client}}},
+		}
+		for _, res := range ress {
+			res.ensureLists()
+		}
+
+		traffic := []string{}
+		for _, tr := range AllowedTraffic(ress) {
+			traffic = append(traffic, fmt.Sprintf("%s/%s->%s/%s", tr.FromNamespace, tr.From, tr.ToNamespace, tr.To))
+		}
+		if strings.Join(tc.expected, ",") != strings.Join(traffic, ",") {
+			t.Fatalf("[%s] AllowedTraffic doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, traffic)
+		}
+	}
+}
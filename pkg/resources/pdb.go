@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"os"
+
+	policyv1 "k8s.io/api/policy/v1"
+)
+
+// ProtectedPods returns the names of the pods in r that the pod disruption budget protects
+// PodDisruptionBudgets without selector protect no pods, like policy/v1 does.
+func (r *Resources) ProtectedPods(pdb *policyv1.PodDisruptionBudget) []string {
+	names, err := r.PodIndex().Match(pdb.Spec.Selector)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid selector in poddisruptionbudget %s: %v\n", pdb.Name, err)
+		return []string{}
+	}
+	return names
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProtectedPods(t *testing.T) {
+	res := &Resources{Namespace: testns, Pods: &corev1.PodList{Items: []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web1", Labels: map[string]string{"app": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web2", Labels: map[string]string{"app": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db", Labels: map[string]string{"app": "db"}}},
+	}}}
+
+	testCases := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		expected []string
+	}{
+		{
+			name:     "Pods matching the selector",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			expected: []string{"web1", "web2"},
+		},
+		{
+			name:     "Empty selector protects all pods",
+			selector: &metav1.LabelSelector{},
+			expected: []string{"web1", "web2", "db"},
+		},
+		{
+			name:     "No selector protects no pods",
+			selector: nil,
+			expected: []string{},
+		},
+		{
+			name:     "Invalid selector",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Invalid"}}},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pdb"},
+			Spec: policyv1.PodDisruptionBudgetSpec{Selector: tc.selector}}
+		pods := res.ProtectedPods(pdb)
+		if strings.Join(pods, ",") != strings.Join(tc.expected, ",") {
+			t.Fatalf("[%s] ProtectedPods doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, pods)
+		}
+	}
+}
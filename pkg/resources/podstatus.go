@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodStatus represents the status of a pod to draw, which summarizes the phase,
+// the container statuses and the deletion of the pod
+type PodStatus string
+
+const (
+	// PodStatusUnknown is for the pods whose status isn't reported yet
+	PodStatusUnknown PodStatus = ""
+	// PodStatusRunning is for the running pods whose containers are all ready
+	PodStatusRunning PodStatus = "Running"
+	// PodStatusNotReady is for the running pods that have containers not ready
+	PodStatusNotReady PodStatus = "NotReady"
+	// PodStatusPending is for the pods that aren't running yet, like the ones not scheduled
+	PodStatusPending PodStatus = "Pending"
+	// PodStatusCrashLoopBackOff is for the pods that have containers restarted repeatedly
+	PodStatusCrashLoopBackOff PodStatus = "CrashLoopBackOff"
+	// PodStatusImagePullBackOff is for the pods that have containers whose images can't be pulled
+	PodStatusImagePullBackOff PodStatus = "ImagePullBackOff"
+	// PodStatusOOMKilled is for the pods that have containers killed for out of memory
+	PodStatusOOMKilled PodStatus = "OOMKilled"
+	// PodStatusCompleted is for the pods whose containers all succeeded
+	PodStatusCompleted PodStatus = "Completed"
+	// PodStatusFailed is for the pods that failed, like the ones with containers exited with errors
+	PodStatusFailed PodStatus = "Failed"
+	// PodStatusTerminating is for the pods that are being deleted
+	PodStatusTerminating PodStatus = "Terminating"
+)
+
+const (
+	reasonCrashLoopBackOff = "CrashLoopBackOff"
+	reasonImagePullBackOff = "ImagePullBackOff"
+	reasonErrImagePull     = "ErrImagePull"
+	reasonOOMKilled        = "OOMKilled"
+)
+
+// GetPodStatus returns the status of the pod from its phase, container statuses and deletionTimestamp
+// Terminating is preferred to the others, and problems of containers are preferred to the phase,
+// so that the reason why the pod isn't running is shown.
+func GetPodStatus(pod *corev1.Pod) PodStatus {
+	if pod.DeletionTimestamp != nil {
+		return PodStatusTerminating
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return PodStatusCompleted
+	case corev1.PodFailed:
+		return PodStatusFailed
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	if status, ok := containerProblem(statuses); ok {
+		return status
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodPending:
+		return PodStatusPending
+	case corev1.PodRunning:
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status != corev1.ConditionTrue {
+				return PodStatusNotReady
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if !status.Ready {
+				return PodStatusNotReady
+			}
+		}
+		return PodStatusRunning
+	}
+	return PodStatusUnknown
+}
+
+// containerProblem returns the status for the problem of the containers, if any
+// OOMKilled is preferred to CrashLoopBackOff, because it's the reason of the crash.
+func containerProblem(statuses []corev1.ContainerStatus) (PodStatus, bool) {
+	found := PodStatusUnknown
+	for _, status := range statuses {
+		if isOOMKilled(status.State.Terminated) ||
+			(status.State.Waiting != nil && status.State.Waiting.Reason == reasonCrashLoopBackOff && isOOMKilled(status.LastTerminationState.Terminated)) {
+			return PodStatusOOMKilled, true
+		}
+		if status.State.Waiting == nil || found != PodStatusUnknown {
+			continue
+		}
+		switch status.State.Waiting.Reason {
+		case reasonCrashLoopBackOff:
+			found = PodStatusCrashLoopBackOff
+		case reasonImagePullBackOff, reasonErrImagePull:
+			found = PodStatusImagePullBackOff
+		}
+	}
+	return found, found != PodStatusUnknown
+}
+
+// isOOMKilled returns whether the container was terminated for out of memory
+func isOOMKilled(terminated *corev1.ContainerStateTerminated) bool {
+	return terminated != nil && terminated.Reason == reasonOOMKilled
+}
+
+// IsProblem returns whether the status is a problem to be noticed, like CrashLoopBackOff
+func (s PodStatus) IsProblem() bool {
+	switch s {
+	case PodStatusCrashLoopBackOff, PodStatusImagePullBackOff, PodStatusOOMKilled, PodStatusFailed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodStatus(t *testing.T) {
+	waiting := func(reason string) corev1.ContainerState {
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+	}
+	terminated := func(reason string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason}}
+	}
+
+	testCases := []struct {
+		name     string
+		pod      corev1.Pod
+		expected PodStatus
+	}{
+		{
+			name:     "Status isn't reported",
+			pod:      corev1.Pod{},
+			expected: PodStatusUnknown,
+		},
+		{
+			name: "Running and ready",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: true}, {Ready: true}}}},
+			expected: PodStatusRunning,
+		},
+		{
+			name: "Running with container not ready",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: true}, {Ready: false}}}},
+			expected: PodStatusNotReady,
+		},
+		{
+			name: "Running with ready condition false",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}}},
+			expected: PodStatusNotReady,
+		},
+		{
+			name:     "Pending",
+			pod:      corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			expected: PodStatusPending,
+		},
+		{
+			name: "CrashLoopBackOff",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: true}, {State: waiting("CrashLoopBackOff")}}}},
+			expected: PodStatusCrashLoopBackOff,
+		},
+		{
+			name: "ImagePullBackOff in init container",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{{State: waiting("ImagePullBackOff")}}}},
+			expected: PodStatusImagePullBackOff,
+		},
+		{
+			name: "ErrImagePull",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{State: waiting("ErrImagePull")}}}},
+			expected: PodStatusImagePullBackOff,
+		},
+		{
+			name: "OOMKilled",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{State: terminated("OOMKilled")}}}},
+			expected: PodStatusOOMKilled,
+		},
+		{
+			name: "CrashLoopBackOff after OOMKilled",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{State: waiting("CrashLoopBackOff"), LastTerminationState: terminated("OOMKilled")}}}},
+			expected: PodStatusOOMKilled,
+		},
+		{
+			name:     "Completed",
+			pod:      corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+			expected: PodStatusCompleted,
+		},
+		{
+			name: "Failed",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{{State: terminated("Error")}}}},
+			expected: PodStatusFailed,
+		},
+		{
+			name: "Terminating is preferred to the others",
+			pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{}},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{{State: waiting("CrashLoopBackOff")}}}},
+			expected: PodStatusTerminating,
+		},
+	}
+
+	for _, tc := range testCases {
+		status := GetPodStatus(&tc.pod)
+		if status != tc.expected {
+			t.Fatalf("[%s] GetPodStatus doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, status)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Clients are the clients to list the objects of kinds
+type Clients struct {
+	Clientset kubernetes.Interface
+	// DynamicClient can be nil, if it isn't specified in Options
+	DynamicClient dynamic.Interface
+	// MetadataClient can be nil, if it isn't specified in Options
+	MetadataClient metadata.Interface
+}
+
+// Kind represents a kind of k8s resources that is collected and drawn as nodes
+type Kind struct {
+	// Name is the short name of the kind, like deploy, used as the names of graphviz nodes
+	Name string
+	// Aliases are the other names of the kind, like deployment, that are normalized to Name
+	Aliases []string
+	// Rank is the rank in the diagram. Kinds in the same rank are placed in the same row.
+	Rank int
+	// Icon is the name of the icon, like deploy for icons/deploy-128.png. Name is used if it's empty.
+	Icon string
+	// List lists the objects of the kind in the namespace of res, and stores them to res.
+	// Kinds registered from outside this package store them with Resources.SetObjects.
+	List func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error
+	// Names returns the names of the objects of the kind in res.
+	// The names stored with Resources.SetObjects are returned if it's nil.
+	Names func(res *Resources) []string
+	// Informer returns the informer to watch the objects of the kind in the namespace of res, for Watcher.
+	// It returns nil if the kind isn't served by the k8s cluster.
+	// Kinds without Informer are watched through the dynamic client if Resource is set,
+	// and kept as they were collected otherwise.
+	Informer func(f InformerFactories, clients Clients, res *Resources) (cache.SharedIndexInformer, error)
+	// Fill stores the objects in the informer to res
+	// The objects are stored with Resources.SetObjects if it's nil.
+	Fill func(res *Resources, objs []interface{})
+	// Resource is the resource to watch the objects of the kind through the dynamic client, if Informer is nil
+	Resource schema.GroupVersionResource
+}
+
+// names returns the names of the objects of the kind in res
+func (k *Kind) names(res *Resources) []string {
+	if k.Names != nil {
+		return k.Names(res)
+	}
+	list := res.Objects(k.Name)
+	if list == nil {
+		return []string{}
+	}
+	return listNames(list)
+}
+
+// registry is the registered kinds in the order of registration
+var registry = struct {
+	sync.RWMutex
+	kinds []Kind
+}{}
+
+// RegisterKind registers the kind to be collected and drawn
+// It returns error if the name or the aliases conflict with the registered kinds.
+func RegisterKind(kind Kind) error {
+	if kind.Name == "" || kind.List == nil || kind.Rank < 0 {
+		return fmt.Errorf("failed to register kind %q: name, list and rank are required", kind.Name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for _, name := range append([]string{kind.Name}, kind.Aliases...) {
+		if _, ok := lookupKind(name); ok || isNamespaceName(name) {
+			return fmt.Errorf("failed to register kind %q: %q is already registered", kind.Name, name)
+		}
+	}
+	registry.kinds = append(registry.kinds, kind)
+	return nil
+}
+
+// Kinds returns the registered kinds in the order of registration
+func Kinds() []Kind {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]Kind{}, registry.kinds...)
+}
+
+// LookupKind returns the registered kind for the name or the alias, case-insensitively
+func LookupKind(name string) (Kind, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	return lookupKind(name)
+}
+
+// lookupKind is LookupKind without lock
+func lookupKind(name string) (Kind, bool) {
+	name = strings.ToLower(name)
+	for _, kind := range registry.kinds {
+		if kind.Name == name {
+			return kind, true
+		}
+		for _, alias := range kind.Aliases {
+			if alias == name {
+				return kind, true
+			}
+		}
+	}
+	return Kind{}, false
+}
+
+// Ranks returns the names of the registered kinds grouped by their ranks
+// Kinds in a rank are in the order of registration, and ranks without kinds are empty.
+func Ranks() [][]string {
+	ranks := [][]string{}
+	for _, kind := range Kinds() {
+		for len(ranks) <= kind.Rank {
+			ranks = append(ranks, []string{})
+		}
+		ranks[kind.Rank] = append(ranks[kind.Rank], kind.Name)
+	}
+	return ranks
+}
+
+// KindIcon returns the name of the icon for the kind
+// It returns kind itself for the kinds that aren't registered, like ns.
+func KindIcon(kind string) string {
+	if k, ok := LookupKind(kind); ok && k.Icon != "" {
+		return k.Icon
+	}
+	return kind
+}
+
+// NewDynamicKind returns the kind whose objects are listed through the dynamic client with gvr
+// It's a shortcut to register kinds, like CRDs, from outside this package.
+func NewDynamicKind(name string, aliases []string, rank int, gvr schema.GroupVersionResource) Kind {
+	return Kind{
+		Name:     name,
+		Aliases:  aliases,
+		Rank:     rank,
+		Resource: gvr,
+		List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			if clients.DynamicClient == nil {
+				return fmt.Errorf("failed to get %s: dynamic client isn't specified", gvr.GroupResource())
+			}
+			list := newUnstructuredList()
+			err := listAll(ctx, list, listOpts, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return clients.DynamicClient.Resource(gvr).Namespace(res.Namespace).List(ctx, opts)
+			})
+			if err != nil {
+				return err
+			}
+			res.SetObjects(name, list)
+			return nil
+		},
+	}
+}
+
+// SetObjects stores the objects of the kind registered from outside this package
+// It's safe to call it from the List funcs, which run concurrently.
+func (r *Resources) SetObjects(kind string, list *unstructured.UnstructuredList) {
+	r.registeredMu.Lock()
+	defer r.registeredMu.Unlock()
+	if r.Registered == nil {
+		r.Registered = map[string]*unstructured.UnstructuredList{}
+	}
+	r.Registered[kind] = list
+}
+
+// Objects returns the objects of the kind stored with SetObjects, or nil if they aren't stored
+func (r *Resources) Objects(kind string) *unstructured.UnstructuredList {
+	r.registeredMu.Lock()
+	defer r.registeredMu.Unlock()
+	return r.Registered[kind]
+}
+
+// listNames returns the names of the objects in list
+func listNames(list runtime.Object) []string {
+	names := []string{}
+	_ = meta.EachListItem(list, func(obj runtime.Object) error {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			names = append(names, accessor.GetName())
+		}
+		return nil
+	})
+	return names
+}
@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// unregisterKind removes the kind registered by the test
+func unregisterKind(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	kinds := []Kind{}
+	for _, kind := range registry.kinds {
+		if kind.Name != name {
+			kinds = append(kinds, kind)
+		}
+	}
+	registry.kinds = kinds
+}
+
+func TestRegisterKind(t *testing.T) {
+	list := func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+		return nil
+	}
+
+	testCases := []struct {
+		name      string
+		kind      Kind
+		expectErr bool
+	}{
+		{
+			name: "New kind",
+			kind: Kind{Name: "widget", Aliases: []string{"widgets"}, Rank: 3, List: list},
+		},
+		{
+			name:      "Name conflicts with built-in kind",
+			kind:      Kind{Name: "pod", Rank: 3, List: list},
+			expectErr: true,
+		},
+		{
+			name:      "Alias conflicts with alias of built-in kind",
+			kind:      Kind{Name: "widget", Aliases: []string{"deployment"}, Rank: 3, List: list},
+			expectErr: true,
+		},
+		{
+			name:      "Name conflicts with namespace",
+			kind:      Kind{Name: "ns", Rank: 3, List: list},
+			expectErr: true,
+		},
+		{
+			name:      "Without list",
+			kind:      Kind{Name: "widget", Rank: 3},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := RegisterKind(tc.kind)
+		if err == nil {
+			unregisterKind(tc.kind.Name)
+		}
+		if tc.expectErr && err == nil {
+			t.Fatalf("[%s] RegisterKind expects error, but returned no error", tc.name)
+		}
+		if !tc.expectErr && err != nil {
+			t.Fatalf("[%s] RegisterKind expects no error, but returned error %v", tc.name, err)
+		}
+	}
+
+	// Built-in kinds are kept after the conflicts
+	if kind, ok := LookupKind("Deployment"); !ok || kind.Name != "deploy" {
+		t.Fatalf("LookupKind doesn't return expected, expected:deploy, returned:%v", kind.Name)
+	}
+}
+
+func TestRanks(t *testing.T) {
+	expected := []string{"hpa cronjob", "deploy job", "sts ds rs rc", "pod", "pvc", "cm secret sa", "rb role", "svc netpol pdb", "httproute grpcroute", "ing gtw"}
+	ranks := []string{}
+	for _, kinds := range Ranks() {
+		ranks = append(ranks, strings.Join(kinds, " "))
+	}
+	if strings.Join(expected, ",") != strings.Join(ranks, ",") {
+		t.Fatalf("Ranks doesn't return expected, expected:%v, returned:%v", expected, ranks)
+	}
+}
+
+func TestDynamicKind(t *testing.T) {
+	kind := NewDynamicKind("rollout", []string{"rollouts"}, 2, schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"})
+	kind.Icon = "crd"
+	if err := RegisterKind(kind); err != nil {
+		t.Fatalf("RegisterKind failed: %v", err)
+	}
+	defer unregisterKind("rollout")
+
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	res, err := NewResourcesWithOptions(NewOfflineClientset(objs), testns, Options{DynamicClient: NewOfflineDynamicClient(objs)})
+	if err != nil {
+		t.Fatalf("NewResourcesWithOptions failed: %v", err)
+	}
+
+	if names := res.GetResourceNames("rollout"); strings.Join(names, ",") != "rollout1" {
+		t.Fatalf("GetResourceNames doesn't return expected, expected:[rollout1], returned:%v", names)
+	}
+	if normalized, err := NormalizeResource("Rollouts"); err != nil || normalized != "rollout" {
+		t.Fatalf("NormalizeResource doesn't return expected, expected:rollout, returned:%v, %v", normalized, err)
+	}
+	if ranks := Ranks(); strings.Join(ranks[2], " ") != "sts ds rs rc rollout" {
+		t.Fatalf("Ranks doesn't return expected, expected:[sts ds rs rc rollout], returned:%v", ranks[2])
+	}
+	if icon := KindIcon("rollout"); icon != "crd" {
+		t.Fatalf("KindIcon doesn't return expected, expected:crd, returned:%v", icon)
+	}
+}
+
+func TestCollectRegisteredKinds(t *testing.T) {
+	names := []string{"widget", "gadget"}
+	for _, name := range names {
+		name := name
+		kind := Kind{Name: name, Rank: 3, List: func(ctx context.Context, clients Clients, res *Resources, listOpts metav1.ListOptions) error {
+			obj := unstructured.Unstructured{}
+			obj.SetName(name + "1")
+			res.SetObjects(name, &unstructured.UnstructuredList{Items: []unstructured.Unstructured{obj}})
+			return nil
+		}}
+		if err := RegisterKind(kind); err != nil {
+			t.Fatalf("RegisterKind failed: %v", err)
+		}
+		defer unregisterKind(name)
+	}
+
+	// Run with -race to detect the concurrent writes to the registered objects
+	for i := 0; i < 10; i++ {
+		res, err := NewCollector(fake.NewSimpleClientset(testRes1...), Options{Workers: len(names)}).Collect(context.TODO(), testns)
+		if err != nil {
+			t.Fatalf("Collect failed: %v", err)
+		}
+		for _, name := range names {
+			if objs := res.GetResourceNames(name); strings.Join(objs, ",") != name+"1" {
+				t.Fatalf("GetResourceNames doesn't return expected, expected:[%s1], returned:%v", name, objs)
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// addRelated adds the resources that are related to the listed resources,
+// but not listed, because they don't match the selectors.
+// Owners are added recursively, like a deployment that owns a replicaset
+// that owns a matching pod, and pvcs, configmaps, secrets and serviceaccounts used by the matching pods are added,
+// as well as the governing services of the statefulsets,
+// so that the graph for the selected resources stays connected.
+// Related resources that fail to be got, like deleted owners of orphaned pods, are skipped with warnings.
+func (r *Resources) addRelated(ctx context.Context, clients Clients) {
+	objs := []metav1.Object{}
+	for i := range r.Pods.Items {
+		objs = append(objs, &r.Pods.Items[i])
+	}
+	for i := range r.Rss.Items {
+		objs = append(objs, &r.Rss.Items[i])
+	}
+	for i := range r.Jobs.Items {
+		objs = append(objs, &r.Jobs.Items[i])
+	}
+	for i := range r.Deploys.Items {
+		objs = append(objs, &r.Deploys.Items[i])
+	}
+	for i := range r.Stss.Items {
+		objs = append(objs, &r.Stss.Items[i])
+	}
+	for i := range r.Dss.Items {
+		objs = append(objs, &r.Dss.Items[i])
+	}
+	for i := range r.Rcs.Items {
+		objs = append(objs, &r.Rcs.Items[i])
+	}
+	for _, extra := range r.Extras {
+		for i := range extra.List.Items {
+			objs = append(objs, &extra.List.Items[i])
+		}
+	}
+
+	// pvcs used by pods
+	for _, pod := range r.Pods.Items {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.VolumeSource.PersistentVolumeClaim == nil || r.HasResource("pvc", vol.VolumeSource.PersistentVolumeClaim.ClaimName) {
+				continue
+			}
+			pvc, err := r.clientset.CoreV1().PersistentVolumeClaims(r.Namespace).Get(ctx, vol.VolumeSource.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to get pvc %s used by pod %s: %v\n", vol.VolumeSource.PersistentVolumeClaim.ClaimName, pod.Name, err)
+				continue
+			}
+			r.Pvcs.Items = append(r.Pvcs.Items, *pvc)
+		}
+	}
+
+	// configmaps, secrets and serviceaccounts used by pods
+	for _, pod := range r.Pods.Items {
+		for _, name := range ConfigMapNames(&pod.Spec) {
+			if r.HasResource("cm", name) {
+				continue
+			}
+			cm, err := r.clientset.CoreV1().ConfigMaps(r.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to get cm %s used by pod %s: %v\n", name, pod.Name, err)
+				continue
+			}
+			r.Cms.Items = append(r.Cms.Items, *cm)
+		}
+		for _, name := range SecretNames(&pod.Spec) {
+			if r.HasResource("secret", name) {
+				continue
+			}
+			secret, err := getSecret(ctx, clients, r.Namespace, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to get secret %s used by pod %s: %v\n", name, pod.Name, err)
+				continue
+			}
+			r.Secrets.Items = append(r.Secrets.Items, *secret)
+		}
+		if name := ServiceAccountName(&pod.Spec); !r.HasResource("sa", name) {
+			sa, err := r.clientset.CoreV1().ServiceAccounts(r.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to get sa %s used by pod %s: %v\n", name, pod.Name, err)
+				continue
+			}
+			r.Sas.Items = append(r.Sas.Items, *sa)
+		}
+	}
+
+	// Owners of objs, and owners of the added owners
+	for len(objs) > 0 {
+		obj := objs[0]
+		objs = objs[1:]
+
+		for _, ref := range obj.GetOwnerReferences() {
+			kind, err := r.NormalizeKind(ref.APIVersion, ref.Kind)
+			if err != nil || r.HasResource(kind, ref.Name) {
+				continue
+			}
+
+			owner, err := r.addOwner(ctx, clients.DynamicClient, kind, ref.Name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to get %s %s that owns %s: %v\n", kind, ref.Name, obj.GetName(), err)
+				continue
+			}
+			if owner != nil {
+				objs = append(objs, owner)
+			}
+		}
+	}
+
+	// governing services of statefulsets, including the ones added as owners
+	for _, sts := range r.Stss.Items {
+		if sts.Spec.ServiceName == "" || r.HasResource("svc", sts.Spec.ServiceName) {
+			continue
+		}
+		svc, err := r.clientset.CoreV1().Services(r.Namespace).Get(ctx, sts.Spec.ServiceName, metav1.GetOptions{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get svc %s governing sts %s: %v\n", sts.Spec.ServiceName, sts.Name, err)
+			continue
+		}
+		r.Svcs.Items = append(r.Svcs.Items, *svc)
+	}
+}
+
+// addOwner gets the resource of the kind and the name, and adds it to r.
+// It returns nil, if the kind can't be an owner in this tool.
+func (r *Resources) addOwner(ctx context.Context, client dynamic.Interface, kind, name string) (metav1.Object, error) {
+	ns := r.Namespace
+
+	switch kind {
+	case "rs":
+		o, err := r.clientset.AppsV1().ReplicaSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.Rss.Items = append(r.Rss.Items, *o)
+		return o, nil
+	case "deploy":
+		o, err := r.clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.Deploys.Items = append(r.Deploys.Items, *o)
+		return o, nil
+	case "sts":
+		o, err := r.clientset.AppsV1().StatefulSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.Stss.Items = append(r.Stss.Items, *o)
+		return o, nil
+	case "ds":
+		o, err := r.clientset.AppsV1().DaemonSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.Dss.Items = append(r.Dss.Items, *o)
+		return o, nil
+	case "rc":
+		o, err := r.clientset.CoreV1().ReplicationControllers(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.Rcs.Items = append(r.Rcs.Items, *o)
+		return o, nil
+	case "job":
+		o, err := r.clientset.BatchV1().Jobs(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.Jobs.Items = append(r.Jobs.Items, *o)
+		return o, nil
+	case "cronjob":
+		o, err := r.clientset.BatchV1().CronJobs(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		r.CronJobs.Items = append(r.CronJobs.Items, *o)
+		return o, nil
+	}
+
+	for _, extra := range r.Extras {
+		if extra.Name() != kind {
+			continue
+		}
+		o, err := client.Resource(extra.Resource).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		extra.List.Items = append(extra.List.Items, *o)
+		return o, nil
+	}
+
+	return nil, nil
+}
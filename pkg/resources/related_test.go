@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var (
+	testRes2 = []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod1",
+			Labels:          map[string]string{"app": "foo"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1"}}},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+				{
+					Name: "vol1",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: "pvc1",
+						},
+					},
+				},
+			}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod2",
+			Labels: map[string]string{"app": "bar"}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pvc1"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pvc2"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "rs1",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "deploy1"}}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "deploy1"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "deploy2"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db-0",
+			Labels:          map[string]string{"app": "db"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db"}}}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db"},
+			Spec: appsv1.StatefulSetSpec{ServiceName: "db-headless"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "db-headless"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "web"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "orphan1",
+			Labels:          map[string]string{"app": "orphan"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "deleted-rs"}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "legacy-abcde",
+			Labels:          map[string]string{"app": "legacy"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ReplicationController", Name: "legacy"}}}},
+		&corev1.ReplicationController{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "legacy"}},
+		&corev1.ReplicationController{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "legacy2"}},
+	}
+)
+
+func TestSelectors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     Options
+		kind     string
+		expected []string
+	}{
+		{
+			name:     "Pods are filtered with label selector",
+			opts:     Options{LabelSelector: "app=foo"},
+			kind:     "pod",
+			expected: []string{"pod1"},
+		},
+		{
+			name:     "Owner of the selected pod is added",
+			opts:     Options{LabelSelector: "app=foo"},
+			kind:     "rs",
+			expected: []string{"rs1"},
+		},
+		{
+			name:     "Owner of the added owner is added",
+			opts:     Options{LabelSelector: "app=foo"},
+			kind:     "deploy",
+			expected: []string{"deploy1"},
+		},
+		{
+			name:     "Pvc used by the selected pod is added",
+			opts:     Options{LabelSelector: "app=foo"},
+			kind:     "pvc",
+			expected: []string{"pvc1"},
+		},
+		{
+			name:     "Governing service of the statefulset added as owner is added",
+			opts:     Options{LabelSelector: "app=db"},
+			kind:     "svc",
+			expected: []string{"db-headless"},
+		},
+		{
+			name:     "Replication controller that owns the selected pod is added",
+			opts:     Options{LabelSelector: "app=legacy"},
+			kind:     "rc",
+			expected: []string{"legacy"},
+		},
+		{
+			name:     "Pod whose owner fails to be got is kept without the owner",
+			opts:     Options{LabelSelector: "app=orphan"},
+			kind:     "pod",
+			expected: []string{"orphan1"},
+		},
+		{
+			name:     "All deployments are got without selectors",
+			opts:     Options{},
+			kind:     "deploy",
+			expected: []string{"deploy1", "deploy2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cs := fake.NewSimpleClientset(testRes2...)
+		res, err := NewResourcesWithOptions(cs, testns, tc.opts)
+		if err != nil {
+			t.Fatalf("[%s] NewResourcesWithOptions failed: %v", tc.name, err)
+		}
+
+		resNames := res.GetResourceNames(tc.kind)
+		if len(tc.expected) != len(resNames) {
+			t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, resNames)
+		}
+		for i := range tc.expected {
+			if tc.expected[i] != resNames[i] {
+				t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, resNames)
+			}
+		}
+	}
+}
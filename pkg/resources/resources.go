@@ -6,189 +6,223 @@ package resources
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 
 	appsv1 "k8s.io/api/apps/v1"
-	autov1 "k8s.io/api/autoscaling/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 )
 
-var (
-	// ResourceTypes represents the set of resource types.
-	// Resouces are grouped by the same level of abstraction.
-	ResourceTypes   = []string{"hpa cronjob", "deploy job", "sts ds rs", "pod", "pvc", "svc", "ing"}
-	normalizedNames = map[string]string{
-		"ns":      "namespace",
-		"svc":     "service",
-		"pvc":     "persistentvolumeclaim",
-		"pod":     "po",
-		"sts":     "statefulset",
-		"ds":      "daemonset",
-		"rs":      "replicaset",
-		"deploy":  "deployment",
-		"job":     "job",
-		"cronjob": "cj",
-		"ing":     "ingress",
-		"hpa":     "horizontalpodautoscaler"}
-)
-
 // Resources represents the k8s resources
 type Resources struct {
 	clientset kubernetes.Interface
-	Namespace string
-
-	Svcs      *corev1.ServiceList
-	Pvcs      *corev1.PersistentVolumeClaimList
-	Pods      *corev1.PodList
-	Stss      *appsv1.StatefulSetList
-	Dss       *appsv1.DaemonSetList
-	Rss       *appsv1.ReplicaSetList
-	Deploys   *appsv1.DeploymentList
-	Jobs      *batchv1.JobList
-	CronJobs  *batchv1.CronJobList
-	Ingresses *netv1.IngressList
-	Hpas      *autov1.HorizontalPodAutoscalerList
+	Namespace string `json:"namespace"`
+	// NamespaceLabels are the labels of the namespace, used for namespaceSelector of NetworkPolicies
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+
+	Svcs      *corev1.ServiceList               `json:"svcs"`
+	Pvcs      *corev1.PersistentVolumeClaimList `json:"pvcs"`
+	Cms       *corev1.ConfigMapList             `json:"cms"`
+	Secrets   *corev1.SecretList                `json:"secrets"`
+	Sas       *corev1.ServiceAccountList        `json:"sas"`
+	Roles     *rbacv1.RoleList                  `json:"roles"`
+	Rbs       *rbacv1.RoleBindingList           `json:"rbs"`
+	Pods      *corev1.PodList                   `json:"pods"`
+	Stss      *appsv1.StatefulSetList           `json:"stss"`
+	Dss       *appsv1.DaemonSetList             `json:"dss"`
+	Rss       *appsv1.ReplicaSetList            `json:"rss"`
+	Deploys   *appsv1.DeploymentList            `json:"deploys"`
+	Jobs      *batchv1.JobList                  `json:"jobs"`
+	CronJobs  *batchv1.CronJobList              `json:"cronJobs"`
+	Ingresses *netv1.IngressList                `json:"ingresses"`
+	Netpols   *netv1.NetworkPolicyList          `json:"netpols"`
+	Rcs       *corev1.ReplicationControllerList `json:"rcs"`
+	Pdbs      *policyv1.PodDisruptionBudgetList `json:"pdbs"`
+	// ResourceQuotas and LimitRanges aren't drawn as nodes, but shown on the namespace
+	ResourceQuotas *corev1.ResourceQuotaList `json:"resourceQuotas"`
+	LimitRanges    *corev1.LimitRangeList    `json:"limitRanges"`
+	// Pvs and StorageClasses are only collected if Options.Storage is specified.
+	// Pvs are the cluster-scoped PersistentVolumes claimed from the namespace.
+	Pvs            *corev1.PersistentVolumeList `json:"pvs"`
+	StorageClasses *storagev1.StorageClassList  `json:"storageClasses"`
+	// EndpointSlices are only collected if Options.EndpointSlices is specified
+	EndpointSlices *discoveryv1.EndpointSliceList `json:"endpointSlices"`
+	// Hpas are autoscaling/v2, which are converted from autoscaling/v1 for older k8s clusters
+	Hpas *autov2.HorizontalPodAutoscalerList `json:"hpas"`
+
+	// Gateway API resources are collected through the dynamic client, if the k8s cluster serves them
+	Gateways        *unstructured.UnstructuredList `json:"gateways"`
+	HTTPRoutes      *unstructured.UnstructuredList `json:"httpRoutes"`
+	GRPCRoutes      *unstructured.UnstructuredList `json:"grpcRoutes"`
+	ReferenceGrants *unstructured.UnstructuredList `json:"referenceGrants"`
+
+	// Extras are the resources of kinds that aren't built in this tool, like CRD
+	Extras []*ExtraResources `json:"extras"`
+	// Registered are the resources of kinds registered from outside this package, by their names
+	Registered map[string]*unstructured.UnstructuredList `json:"registered,omitempty"`
+
+	// Invisible are the kinds that were skipped, because they are forbidden or unsupported
+	Invisible []string `json:"invisible,omitempty"`
+
+	// registeredMu guards Registered, which is written by the concurrent List calls of the registered kinds
+	registeredMu sync.Mutex
+	// podIndex is the index of pods by labels, which is built on the first use
+	podIndex *LabelIndex
+	// hpaV1 is true if hpas were got through autoscaling/v1, because autoscaling/v2 isn't served
+	hpaV1 bool
 }
 
 // NewResources resturns Resources for the namespace
 func NewResources(clientset kubernetes.Interface, namespace string) (*Resources, error) {
-	var err error
-	res := &Resources{clientset: clientset, Namespace: namespace}
+	return NewResourcesWithOptions(clientset, namespace, Options{})
+}
 
-	// service
-	res.Svcs, err = clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get services in namespace %q: %v", namespace, err)
-	}
+// NewResourcesWithOptions resturns Resources for the namespace with the options
+func NewResourcesWithOptions(clientset kubernetes.Interface, namespace string, opts Options) (*Resources, error) {
+	return NewCollector(clientset, opts).Collect(context.TODO(), namespace)
+}
 
-	// persistentvolumeclaim
-	res.Pvcs, err = clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get persistentVolumeClaims in namespace %q: %v", namespace, err)
+// ensureLists sets empty lists to the lists that aren't set
+func (r *Resources) ensureLists() {
+	if r.Svcs == nil {
+		r.Svcs = &corev1.ServiceList{}
 	}
-
-	// pod
-	res.Pods, err = clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get pods in namespace %q: %v", namespace, err)
+	if r.Pvcs == nil {
+		r.Pvcs = &corev1.PersistentVolumeClaimList{}
 	}
-
-	// statefulset
-	res.Stss, err = clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get statefulsets in namespace %q: %v", namespace, err)
+	if r.Cms == nil {
+		r.Cms = &corev1.ConfigMapList{}
 	}
-
-	// daemonset
-	res.Dss, err = clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get daemonsets in namespace %q: %v", namespace, err)
+	if r.Secrets == nil {
+		r.Secrets = &corev1.SecretList{}
 	}
-
-	// replicaset
-	res.Rss, err = clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get replicasets in namespace %q: %v", namespace, err)
+	if r.Sas == nil {
+		r.Sas = &corev1.ServiceAccountList{}
+	}
+	if r.Roles == nil {
+		r.Roles = &rbacv1.RoleList{}
+	}
+	if r.Rbs == nil {
+		r.Rbs = &rbacv1.RoleBindingList{}
+	}
+	if r.Pods == nil {
+		r.Pods = &corev1.PodList{}
+	}
+	if r.Stss == nil {
+		r.Stss = &appsv1.StatefulSetList{}
+	}
+	if r.Dss == nil {
+		r.Dss = &appsv1.DaemonSetList{}
+	}
+	if r.Rss == nil {
+		r.Rss = &appsv1.ReplicaSetList{}
+	}
+	if r.Deploys == nil {
+		r.Deploys = &appsv1.DeploymentList{}
+	}
+	if r.Jobs == nil {
+		r.Jobs = &batchv1.JobList{}
+	}
+	if r.CronJobs == nil {
+		r.CronJobs = &batchv1.CronJobList{}
+	}
+	if r.Ingresses == nil {
+		r.Ingresses = &netv1.IngressList{}
+	}
+	if r.Netpols == nil {
+		r.Netpols = &netv1.NetworkPolicyList{}
+	}
+	if r.Rcs == nil {
+		r.Rcs = &corev1.ReplicationControllerList{}
+	}
+	if r.Pdbs == nil {
+		r.Pdbs = &policyv1.PodDisruptionBudgetList{}
+	}
+	if r.ResourceQuotas == nil {
+		r.ResourceQuotas = &corev1.ResourceQuotaList{}
+	}
+	if r.LimitRanges == nil {
+		r.LimitRanges = &corev1.LimitRangeList{}
+	}
+	if r.Pvs == nil {
+		r.Pvs = &corev1.PersistentVolumeList{}
+	}
+	if r.StorageClasses == nil {
+		r.StorageClasses = &storagev1.StorageClassList{}
+	}
+	if r.EndpointSlices == nil {
+		r.EndpointSlices = &discoveryv1.EndpointSliceList{}
+	}
+	if r.Hpas == nil {
+		r.Hpas = &autov2.HorizontalPodAutoscalerList{}
+	}
+	if r.Gateways == nil {
+		r.Gateways = newUnstructuredList()
+	}
+	if r.HTTPRoutes == nil {
+		r.HTTPRoutes = newUnstructuredList()
+	}
+	if r.GRPCRoutes == nil {
+		r.GRPCRoutes = newUnstructuredList()
 	}
-	// Remove old rss from the list
+	if r.ReferenceGrants == nil {
+		r.ReferenceGrants = newUnstructuredList()
+	}
+	if r.Extras == nil {
+		r.Extras = []*ExtraResources{}
+	}
+}
+
+// removeOldRss removes old rss from the list
+func (r *Resources) removeOldRss() {
 	removedList := []appsv1.ReplicaSet{}
-	for _, rs := range res.Rss.Items {
+	for _, rs := range r.Rss.Items {
 		// Old replicaset has both desired replicas and current replicas set to 0
 		if rs.Spec.Replicas != nil && *rs.Spec.Replicas == int32(0) && rs.Status.Replicas == int32(0) {
 			continue
 		}
 		removedList = append(removedList, rs)
 	}
-	res.Rss.Items = removedList
-
-	// deployment
-	res.Deploys, err = clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get deployments in namespace %q: %v", namespace, err)
-	}
-
-	// job
-	res.Jobs, err = clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get jobs in namespace %q: %v", namespace, err)
-	}
-
-	// cronJob
-	res.CronJobs, err = clientset.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cronjobs in namespace %q: %v", namespace, err)
-	}
-
-	// ingress
-	res.Ingresses, err = clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get ingresses in namespace %q: %v", namespace, err)
-	}
+	r.Rss.Items = removedList
+}
 
-	// hpas
-	res.Hpas, err = clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get hpas in namespace %q: %v", namespace, err)
+// PodIndex returns the index of the pods by their labels
+// It is built on the first call, so pods shouldn't be changed after that.
+func (r *Resources) PodIndex() *LabelIndex {
+	if r.podIndex == nil {
+		r.podIndex = NewLabelIndex()
+		for _, pod := range r.Pods.Items {
+			r.podIndex.Add(pod.Name, pod.Labels)
+		}
 	}
-
-	return res, nil
+	return r.podIndex
 }
 
 // GetResourceNames returns the resource names of the kind
+// kind is the name of a registered kind or an extra kind.
 func (r *Resources) GetResourceNames(kind string) []string {
-	names := []string{}
+	if k, ok := LookupKind(kind); ok && k.Name == kind {
+		return k.names(r)
+	}
 
-	switch kind {
-	case "svc":
-		for _, n := range r.Svcs.Items {
-			names = append(names, n.Name)
-		}
-	case "pvc":
-		for _, n := range r.Pvcs.Items {
-			names = append(names, n.Name)
-		}
-	case "pod":
-		for _, n := range r.Pods.Items {
-			names = append(names, n.Name)
-		}
-	case "sts":
-		for _, n := range r.Stss.Items {
-			names = append(names, n.Name)
-		}
-	case "ds":
-		for _, n := range r.Dss.Items {
-			names = append(names, n.Name)
-		}
-	case "rs":
-		for _, n := range r.Rss.Items {
-			names = append(names, n.Name)
-		}
-	case "deploy":
-		for _, n := range r.Deploys.Items {
-			names = append(names, n.Name)
-		}
-	case "job":
-		for _, n := range r.Jobs.Items {
-			names = append(names, n.Name)
-		}
-	case "cronjob":
-		for _, n := range r.CronJobs.Items {
-			names = append(names, n.Name)
-		}
-	case "ing":
-		for _, n := range r.Ingresses.Items {
-			names = append(names, n.Name)
+	names := []string{}
+	for _, extra := range r.Extras {
+		if extra.Name() != kind {
+			continue
 		}
-	case "hpa":
-		for _, n := range r.Hpas.Items {
-			names = append(names, n.Name)
+		for _, n := range extra.List.Items {
+			names = append(names, n.GetName())
 		}
 	}
-
 	return names
 }
 
@@ -204,15 +238,28 @@ func (r *Resources) HasResource(kind, name string) bool {
 
 // NormalizeResource resturns normalized name of the resource.
 // It returns error if it fails to normalize the resource name.
-// key of normalizedNames map is used as the normalized name.
+// Name of the registered kind is used as the normalized name.
 func NormalizeResource(resource string) (string, error) {
-	for k, v := range normalizedNames {
-		if k == strings.ToLower(resource) {
-			return k, nil
-		}
-		if v == strings.ToLower(resource) {
-			return k, nil
-		}
+	if kind, ok := LookupKind(resource); ok {
+		return kind.Name, nil
+	}
+	if isNamespaceName(resource) {
+		return "ns", nil
 	}
 	return "", fmt.Errorf("failed to find normalized resource name for %s", resource)
 }
+
+// NormalizeKind resturns normalized name of the kind in the apiVersion.
+// Unlike NormalizeResource, it also handles the kinds of Extras.
+// It returns error if it fails to normalize the kind.
+func (r *Resources) NormalizeKind(apiVersion, kind string) (string, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err == nil {
+		for _, extra := range r.Extras {
+			if extra.Resource.Group == gv.Group && extra.Kind == kind {
+				return extra.Name(), nil
+			}
+		}
+	}
+	return NormalizeResource(kind)
+}
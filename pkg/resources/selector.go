@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LabelIndex indexes objects by the keys of their labels, to find the objects that selectors match.
+// Objects are looked up through the keys that selectors require, then checked against all requirements,
+// so that selecting from many objects doesn't need to check all of them.
+type LabelIndex struct {
+	names  []string
+	labels []map[string]string
+	// byKey has the positions of the objects that have the label key
+	byKey map[string][]int
+}
+
+// NewLabelIndex returns an empty LabelIndex
+func NewLabelIndex() *LabelIndex {
+	return &LabelIndex{byKey: map[string][]int{}}
+}
+
+// Add adds the object with the name and the labels to the index
+func (idx *LabelIndex) Add(name string, labels map[string]string) {
+	pos := len(idx.names)
+	idx.names = append(idx.names, name)
+	idx.labels = append(idx.labels, labels)
+	for key := range labels {
+		idx.byKey[key] = append(idx.byKey[key], pos)
+	}
+}
+
+// Match returns the names of the objects that the selector matches, in the order of addition.
+// It follows the semantics of metav1.LabelSelector: matchLabels and matchExpressions are ANDed,
+// and an empty selector matches all objects. nil selector matches nothing.
+// It returns error if the selector is invalid, like In without values.
+func (idx *LabelIndex) Match(selector *metav1.LabelSelector) ([]string, error) {
+	if selector == nil {
+		return []string{}, nil
+	}
+	reqs, err := requirements(selector)
+	if err != nil {
+		return nil, err
+	}
+	return idx.match(reqs), nil
+}
+
+// MatchLabels returns the names of the objects that have all the labels in the selector,
+// like the selector of services. Empty selector matches all objects.
+func (idx *LabelIndex) MatchLabels(selector map[string]string) []string {
+	return idx.match(requirementsFromLabels(selector))
+}
+
+// match returns the names of the objects that satisfy all the requirements
+func (idx *LabelIndex) match(reqs []requirement) []string {
+	// Candidates are the objects with the key required to exist, and the fewest of them are used.
+	// All objects are candidates, if no key is required to exist, like only NotIn or DoesNotExist.
+	var candidates []int
+	narrowed := false
+	for _, req := range reqs {
+		if !req.needsKey() {
+			continue
+		}
+		positions := idx.byKey[req.key]
+		if !narrowed || len(positions) < len(candidates) {
+			candidates = positions
+			narrowed = true
+		}
+	}
+	if !narrowed {
+		candidates = make([]int, len(idx.names))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	matched := []int{}
+	for _, pos := range candidates {
+		ok := true
+		for _, req := range reqs {
+			if !req.matches(idx.labels[pos]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, pos)
+		}
+	}
+
+	sort.Ints(matched)
+	names := []string{}
+	for _, pos := range matched {
+		names = append(names, idx.names[pos])
+	}
+	return names
+}
+
+// requirement represents a requirement for a label key
+type requirement struct {
+	key      string
+	operator metav1.LabelSelectorOperator
+	values   map[string]bool
+}
+
+// requirements returns the requirements in the selector
+func requirements(selector *metav1.LabelSelector) ([]requirement, error) {
+	reqs := requirementsFromLabels(selector.MatchLabels)
+	for _, expr := range selector.MatchExpressions {
+		values := map[string]bool{}
+		for _, v := range expr.Values {
+			values[v] = true
+		}
+
+		switch expr.Operator {
+		case metav1.LabelSelectorOpIn, metav1.LabelSelectorOpNotIn:
+			if len(values) == 0 {
+				return nil, fmt.Errorf("values must be specified for operator %s of key %q", expr.Operator, expr.Key)
+			}
+		case metav1.LabelSelectorOpExists, metav1.LabelSelectorOpDoesNotExist:
+			if len(values) != 0 {
+				return nil, fmt.Errorf("values must not be specified for operator %s of key %q", expr.Operator, expr.Key)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operator %q of key %q", expr.Operator, expr.Key)
+		}
+		reqs = append(reqs, requirement{key: expr.Key, operator: expr.Operator, values: values})
+	}
+	return reqs, nil
+}
+
+// requirementsFromLabels returns the requirements for the labels to be equal
+func requirementsFromLabels(selector map[string]string) []requirement {
+	reqs := []requirement{}
+	for k, v := range selector {
+		reqs = append(reqs, requirement{key: k, operator: metav1.LabelSelectorOpIn, values: map[string]bool{v: true}})
+	}
+	return reqs
+}
+
+// needsKey returns whether the requirement is satisfied only by the labels with the key
+func (r requirement) needsKey() bool {
+	return r.operator == metav1.LabelSelectorOpIn || r.operator == metav1.LabelSelectorOpExists
+}
+
+// matches returns whether the labels satisfy the requirement
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.operator {
+	case metav1.LabelSelectorOpIn:
+		return ok && r.values[value]
+	case metav1.LabelSelectorOpNotIn:
+		return !ok || !r.values[value]
+	case metav1.LabelSelectorOpExists:
+		return ok
+	case metav1.LabelSelectorOpDoesNotExist:
+		return !ok
+	}
+	return false
+}
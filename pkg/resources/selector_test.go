@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLabelIndex(t *testing.T) {
+	idx := NewLabelIndex()
+	idx.Add("web1", map[string]string{"app": "web", "tier": "frontend"})
+	idx.Add("web2", map[string]string{"app": "web", "tier": "frontend", "canary": "true"})
+	idx.Add("db1", map[string]string{"app": "db", "tier": "backend"})
+	idx.Add("job1", map[string]string{})
+
+	testCases := []struct {
+		name      string
+		selector  *metav1.LabelSelector
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "nil selector",
+			selector: nil,
+			expected: []string{},
+		},
+		{
+			name:     "Empty selector",
+			selector: &metav1.LabelSelector{},
+			expected: []string{"web1", "web2", "db1", "job1"},
+		},
+		{
+			name:     "matchLabels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			expected: []string{"web1", "web2"},
+		},
+		{
+			name: "In",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"frontend", "backend"}},
+			}},
+			expected: []string{"web1", "web2", "db1"},
+		},
+		{
+			name: "NotIn also matches objects without the key",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"web"}},
+			}},
+			expected: []string{"db1", "job1"},
+		},
+		{
+			name: "Exists",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "canary", Operator: metav1.LabelSelectorOpExists},
+			}},
+			expected: []string{"web2"},
+		},
+		{
+			name: "DoesNotExist",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "canary", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			expected: []string{"web1", "db1", "job1"},
+		},
+		{
+			name: "matchLabels and matchExpressions are ANDed",
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "frontend"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "canary", Operator: metav1.LabelSelectorOpDoesNotExist},
+					{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"web", "db"}},
+				},
+			},
+			expected: []string{"web1"},
+		},
+		{
+			name:     "Un-known key",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"unknown": "web"}},
+			expected: []string{},
+		},
+		{
+			name: "In without values",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpIn},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "Exists with values",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpExists, Values: []string{"web"}},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "Un-known operator",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: "Unknown", Values: []string{"web"}},
+			}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		names, err := idx.Match(tc.selector)
+		if tc.expectErr {
+			if err == nil {
+				t.Fatalf("[%s] Match expects error, but returned no error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] Match failed: %v", tc.name, err)
+		}
+		if strings.Join(tc.expected, ",") != strings.Join(names, ",") {
+			t.Fatalf("[%s] Match doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, names)
+		}
+	}
+}
+
+func TestLabelIndexMatchLabels(t *testing.T) {
+	idx := NewLabelIndex()
+	idx.Add("web1", map[string]string{"app": "web", "tier": "frontend"})
+	idx.Add("web2", map[string]string{"app": "web"})
+	idx.Add("db1", map[string]string{"app": "db", "tier": "backend"})
+
+	testCases := []struct {
+		name     string
+		selector map[string]string
+		expected []string
+	}{
+		{
+			name:     "Empty selector",
+			selector: map[string]string{},
+			expected: []string{"web1", "web2", "db1"},
+		},
+		{
+			name:     "One label",
+			selector: map[string]string{"app": "web"},
+			expected: []string{"web1", "web2"},
+		},
+		{
+			name:     "Two labels",
+			selector: map[string]string{"app": "web", "tier": "frontend"},
+			expected: []string{"web1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		names := idx.MatchLabels(tc.selector)
+		if strings.Join(tc.expected, ",") != strings.Join(names, ",") {
+			t.Fatalf("[%s] MatchLabels doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, names)
+		}
+	}
+}
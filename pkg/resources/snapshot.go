@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// SnapshotVersion is the version of the snapshot format written by this tool
+	// It should be changed when the format changes in an incompatible way.
+	SnapshotVersion = "k8sviz/v1"
+)
+
+// Snapshot represents the resources collected from k8s cluster at a time,
+// to be drawn later without k8s cluster
+type Snapshot struct {
+	// Version is the version of the snapshot format
+	Version string `json:"version"`
+	// Context is the kubeconfig context of k8s cluster that the resources were collected from
+	Context string `json:"context,omitempty"`
+	// Timestamp is the time when the resources were collected
+	Timestamp time.Time `json:"timestamp"`
+	// Resources are the resources for each namespace
+	Resources []*Resources `json:"resources"`
+}
+
+// NewSnapshot returns a Snapshot of ress collected from k8s cluster in the context now
+func NewSnapshot(context string, ress []*Resources) *Snapshot {
+	return &Snapshot{
+		Version:   SnapshotVersion,
+		Context:   context,
+		Timestamp: time.Now().UTC(),
+		Resources: ress,
+	}
+}
+
+// Write writes the snapshot to w as JSON
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteFile writes the snapshot to the file
+func (s *Snapshot) WriteFile(path string) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	if err := s.Write(f); err != nil {
+		if closeErr := f.Close(); closeErr != nil {
+			return fmt.Errorf("failed to close file after write failure: %v, %v", closeErr, err)
+		}
+		return err
+	}
+
+	return f.Close()
+}
+
+// ReadSnapshot returns the snapshot read from r
+// It fails if the snapshot is written in a different version of the format.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %q, expected %q", s.Version, SnapshotVersion)
+	}
+
+	// Snapshots written by other tools may lack some lists
+	for _, res := range s.Resources {
+		res.ensureLists()
+	}
+	return s, nil
+}
+
+// ReadSnapshotFile returns the snapshot read from the file
+func ReadSnapshotFile(path string) (*Snapshot, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadSnapshot(f)
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	res, err := NewResourcesWithOptions(NewOfflineClientset(objs), testns, Options{
+		DynamicClient: NewOfflineDynamicClient(objs),
+		ExtraKinds:    []string{"rollouts.argoproj.io"},
+	})
+	if err != nil {
+		t.Fatalf("NewResourcesWithOptions failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewSnapshot("test-context", []*Resources{res}).Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	snap, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+
+	if snap.Context != "test-context" {
+		t.Fatalf("ReadSnapshot doesn't return expected context, expected:%v, returned:%v", "test-context", snap.Context)
+	}
+	if len(snap.Resources) != 1 || snap.Resources[0].Namespace != testns {
+		t.Fatalf("ReadSnapshot doesn't return expected resources, expected namespace:%v, returned:%v", testns, snap.Resources)
+	}
+	for _, kind := range []string{"svc", "pvc", "cm", "secret", "sa", "role", "rb", "pod", "sts", "ds", "rs", "deploy", "job", "cronjob", "ing", "hpa", "gtw", "httproute", "grpcroute", "rollout.argoproj.io"} {
+		expected := res.GetResourceNames(kind)
+		returned := snap.Resources[0].GetResourceNames(kind)
+		if strings.Join(expected, ",") != strings.Join(returned, ",") {
+			t.Fatalf("[%s] GetResourceNames for snapshot doesn't return expected, expected:%v, returned:%v", kind, expected, returned)
+		}
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	testCases := []struct {
+		name      string
+		snapshot  string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "Snapshot with pod1 in testns",
+			snapshot: `{"version": "k8sviz/v1", "resources": [{"namespace": "testns", "pods": {"items": [{"metadata": {"name": "pod1", "namespace": "testns"}}]}}]}`,
+			expected: []string{"pod1"},
+		},
+		{
+			name:      "Snapshot with un-known version",
+			snapshot:  `{"version": "k8sviz/v0", "resources": []}`,
+			expectErr: true,
+		},
+		{
+			name:      "Broken snapshot",
+			snapshot:  `{"version": `,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		snap, err := ReadSnapshot(strings.NewReader(tc.snapshot))
+		if tc.expectErr {
+			if err == nil {
+				t.Fatalf("[%s] ReadSnapshot expects error, but returned no error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] ReadSnapshot failed: %v", tc.name, err)
+		}
+
+		// Lists that aren't in the snapshot are empty
+		if names := snap.Resources[0].GetResourceNames("svc"); len(names) != 0 {
+			t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:[], returned:%v", tc.name, names)
+		}
+		names := snap.Resources[0].GetResourceNames("pod")
+		if strings.Join(tc.expected, ",") != strings.Join(names, ",") {
+			t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, names)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// TemplateClaimNames returns the names of the pvcs in r that are created from the volumeClaimTemplates of the statefulset
+// The pvcs are named <template>-<statefulset>-<ordinal>, like data-web-0, and they are kept after the pods are deleted.
+func (r *Resources) TemplateClaimNames(sts *appsv1.StatefulSet) []string {
+	names := []string{}
+	for _, tmpl := range sts.Spec.VolumeClaimTemplates {
+		prefix := tmpl.Name + "-" + sts.Name + "-"
+		for _, pvc := range r.Pvcs.Items {
+			if strings.HasPrefix(pvc.Name, prefix) && isOrdinal(strings.TrimPrefix(pvc.Name, prefix)) {
+				names = append(names, pvc.Name)
+			}
+		}
+	}
+	return names
+}
+
+// isOrdinal returns whether s is the ordinal of a pod of statefulset, like 0
+func isOrdinal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
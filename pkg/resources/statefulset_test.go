@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTemplateClaimNames(t *testing.T) {
+	pvcs := []corev1.PersistentVolumeClaim{}
+	for _, name := range []string{"data-web-0", "data-web-1", "logs-web-0", "data-web-backup", "data-web-api-0", "data-web-"} {
+		pvcs = append(pvcs, corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: name}})
+	}
+	res := &Resources{Namespace: testns, Pvcs: &corev1.PersistentVolumeClaimList{Items: pvcs}}
+
+	testCases := []struct {
+		name      string
+		sts       string
+		templates []string
+		expected  []string
+	}{
+		{
+			name:      "Pvcs for each ordinal and template",
+			sts:       "web",
+			templates: []string{"data", "logs"},
+			expected:  []string{"data-web-0", "data-web-1", "logs-web-0"},
+		},
+		{
+			name:      "Pvcs for the statefulset whose name is the prefix of another one aren't matched",
+			sts:       "web-api",
+			templates: []string{"data"},
+			expected:  []string{"data-web-api-0"},
+		},
+		{
+			name:      "No volumeClaimTemplates",
+			sts:       "web",
+			templates: []string{},
+			expected:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: tc.sts}}
+		for _, tmpl := range tc.templates {
+			sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: tmpl}})
+		}
+		names := res.TemplateClaimNames(sts)
+		if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+			t.Fatalf("[%s] TemplateClaimNames doesn't return expected, expected:%v, returned:%v", tc.name, tc.expected, names)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// claimedPvs returns the persistent volumes in pvs that are claimed from the namespace
+// PersistentVolumes are cluster-scoped, so the ones for other namespaces are dropped.
+func claimedPvs(pvs []corev1.PersistentVolume, namespace string) []corev1.PersistentVolume {
+	claimed := []corev1.PersistentVolume{}
+	for _, pv := range pvs {
+		if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Namespace == namespace {
+			claimed = append(claimed, pv)
+		}
+	}
+	return claimed
+}
+
+// BoundPv returns the persistent volume that the claim is bound to through spec.volumeName,
+// or nil if the claim isn't bound or the volume isn't collected
+func (r *Resources) BoundPv(pvc *corev1.PersistentVolumeClaim) *corev1.PersistentVolume {
+	if pvc.Spec.VolumeName == "" {
+		return nil
+	}
+	for i := range r.Pvs.Items {
+		if r.Pvs.Items[i].Name == pvc.Spec.VolumeName {
+			return &r.Pvs.Items[i]
+		}
+	}
+	return nil
+}
+
+// StorageClass returns the storage class with the name, or nil if it isn't collected
+func (r *Resources) StorageClass(name string) *storagev1.StorageClass {
+	for i := range r.StorageClasses.Items {
+		if r.StorageClasses.Items[i].Name == name {
+			return &r.StorageClasses.Items[i]
+		}
+	}
+	return nil
+}
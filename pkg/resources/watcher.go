@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watcher keeps resources in namespaces up to date through shared informers
+type Watcher struct {
+	clientset  kubernetes.Interface
+	opts       Options
+	namespaces []string
+	// SyncTimeout is the timeout to collect resources and to sync the informers on start (0 means no timeout).
+	// Informers keep watching after the sync, regardless of the timeout.
+	SyncTimeout time.Duration
+}
+
+// InformerFactories are the factories of the informers to watch the kinds in a namespace
+type InformerFactories struct {
+	// Filtered is the factory for the namespace with the selectors in Options
+	Filtered informers.SharedInformerFactory
+	// Unfiltered is the factory for the namespace without the selectors, for the kinds that apply to the whole namespace
+	Unfiltered informers.SharedInformerFactory
+	// Cluster is the factory for the cluster-scoped kinds
+	Cluster informers.SharedInformerFactory
+	// Dynamic is the factory for the namespace with the selectors through the dynamic client.
+	// It's nil if Options.DynamicClient isn't specified.
+	Dynamic dynamicinformer.DynamicSharedInformerFactory
+	// Metadata is the factory for the namespace with the selectors through the metadata client.
+	// It's nil if Options.MetadataClient isn't specified.
+	Metadata metadatainformer.SharedInformerFactory
+}
+
+// watchedKind represents the informer for a kind
+type watchedKind struct {
+	kind  Kind
+	store cache.Store
+}
+
+// watchedNamespace represents the informers for a namespace
+type watchedNamespace struct {
+	// base is the resources collected before starting the informers
+	base *Resources
+	// kinds are the kinds watched through the informers, in the order of Kinds and the auxiliary kinds
+	kinds       []watchedKind
+	extraStores []cache.Store
+}
+
+// NewWatcher returns a Watcher for the namespaces with the options
+func NewWatcher(clientset kubernetes.Interface, namespaces []string, opts Options) *Watcher {
+	return &Watcher{clientset: clientset, opts: opts, namespaces: namespaces}
+}
+
+// Run calls handler with the resources in the namespaces, once the informers are synced
+// and each time objects change, until ctx is done.
+// Changes are debounced, so that handler is called once for changes within debounce.
+// Kinds that aren't visible on the first collection, like forbidden ones, aren't watched.
+func (w *Watcher) Run(ctx context.Context, debounce time.Duration, handler func([]*Resources)) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}
+	tweak := func(o *metav1.ListOptions) {
+		o.LabelSelector = w.opts.LabelSelector
+		o.FieldSelector = w.opts.FieldSelector
+	}
+
+	syncCtx, cancel := ctx, context.CancelFunc(func() {})
+	if w.SyncTimeout > 0 {
+		syncCtx, cancel = context.WithTimeout(ctx, w.SyncTimeout)
+	}
+	defer cancel()
+
+	// Collect once to find the invisible kinds and the resources for the extra kinds
+	collector := NewCollector(w.clientset, w.opts)
+	watched := []*watchedNamespace{}
+	for _, ns := range w.namespaces {
+		res, err := collector.Collect(syncCtx, ns)
+		if err != nil {
+			return err
+		}
+		wn, err := w.watch(ctx, syncCtx, res, handlers, tweak)
+		if err != nil {
+			return err
+		}
+		watched = append(watched, wn)
+	}
+
+	// Changes on the initial sync are drawn by the first call
+	select {
+	case <-changed:
+	default:
+	}
+	handler(w.resources(ctx, watched))
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+			if timer == nil {
+				timer = time.After(debounce)
+			}
+		case <-timer:
+			timer = nil
+			handler(w.resources(ctx, watched))
+		}
+	}
+}
+
+// watch starts the informers for the kinds visible in res, which is collected in a namespace,
+// and waits for them to be synced until syncCtx is done. Informers run until ctx is done.
+func (w *Watcher) watch(ctx, syncCtx context.Context, res *Resources, handlers cache.ResourceEventHandler, tweak func(*metav1.ListOptions)) (*watchedNamespace, error) {
+	ns := res.Namespace
+	wn := &watchedNamespace{base: res}
+	invisible := map[string]bool{}
+	for _, kind := range res.Invisible {
+		invisible[kind] = true
+	}
+
+	f := InformerFactories{
+		Filtered:   informers.NewSharedInformerFactoryWithOptions(w.clientset, 0, informers.WithNamespace(ns), informers.WithTweakListOptions(tweak)),
+		Unfiltered: informers.NewSharedInformerFactoryWithOptions(w.clientset, 0, informers.WithNamespace(ns)),
+		Cluster:    informers.NewSharedInformerFactoryWithOptions(w.clientset, 0),
+	}
+	if w.opts.DynamicClient != nil {
+		f.Dynamic = dynamicinformer.NewFilteredDynamicSharedInformerFactory(w.opts.DynamicClient, 0, ns, tweak)
+	}
+	if w.opts.MetadataClient != nil {
+		f.Metadata = metadatainformer.NewFilteredSharedInformerFactory(w.opts.MetadataClient, 0, ns, tweak)
+	}
+	clients := Clients{Clientset: w.clientset, DynamicClient: w.opts.DynamicClient, MetadataClient: w.opts.MetadataClient}
+
+	for _, kind := range append(Kinds(), enabledAuxiliaryKinds(w.opts)...) {
+		if invisible[kind.Name] {
+			continue
+		}
+		var informer cache.SharedIndexInformer
+		switch {
+		case kind.Informer != nil:
+			var err error
+			if informer, err = kind.Informer(f, clients, res); err != nil {
+				return nil, err
+			}
+		case !kind.Resource.Empty() && f.Dynamic != nil:
+			// Kinds registered from outside this package
+			informer = f.Dynamic.ForResource(kind.Resource).Informer()
+		}
+		if informer == nil {
+			continue
+		}
+		if err := informer.SetTransform(stripTransform); err != nil {
+			return nil, err
+		}
+		informer.AddEventHandler(handlers)
+		wn.kinds = append(wn.kinds, watchedKind{kind: kind, store: informer.GetStore()})
+	}
+	if f.Dynamic != nil {
+		for _, extra := range res.Extras {
+			informer := f.Dynamic.ForResource(extra.Resource).Informer()
+			if err := informer.SetTransform(stripTransform); err != nil {
+				return nil, err
+			}
+			informer.AddEventHandler(handlers)
+			wn.extraStores = append(wn.extraStores, informer.GetStore())
+		}
+	}
+
+	for _, factory := range []informers.SharedInformerFactory{f.Filtered, f.Unfiltered, f.Cluster} {
+		factory.Start(ctx.Done())
+		for typ, ok := range factory.WaitForCacheSync(syncCtx.Done()) {
+			if !ok {
+				return nil, fmt.Errorf("failed to sync informer for %v in namespace %q: %v", typ, ns, syncCtx.Err())
+			}
+		}
+	}
+	if f.Dynamic != nil {
+		f.Dynamic.Start(ctx.Done())
+		for gvr, ok := range f.Dynamic.WaitForCacheSync(syncCtx.Done()) {
+			if !ok {
+				return nil, fmt.Errorf("failed to sync informer for %v in namespace %q: %v", gvr, ns, syncCtx.Err())
+			}
+		}
+	}
+	if f.Metadata != nil {
+		f.Metadata.Start(ctx.Done())
+		for gvr, ok := range f.Metadata.WaitForCacheSync(syncCtx.Done()) {
+			if !ok {
+				return nil, fmt.Errorf("failed to sync informer for %v in namespace %q: %v", gvr, ns, syncCtx.Err())
+			}
+		}
+	}
+
+	return wn, nil
+}
+
+// resources returns the resources stored in the informers
+func (w *Watcher) resources(ctx context.Context, watched []*watchedNamespace) []*Resources {
+	ress := []*Resources{}
+	for _, wn := range watched {
+		// Objects of the kinds that aren't watched are kept as they were collected
+		res := &Resources{clientset: w.clientset, Namespace: wn.base.Namespace, NamespaceLabels: wn.base.NamespaceLabels, Invisible: wn.base.Invisible, Extras: []*ExtraResources{}, Registered: map[string]*unstructured.UnstructuredList{}}
+		for kind, list := range wn.base.Registered {
+			res.Registered[kind] = list
+		}
+		for _, wk := range wn.kinds {
+			objs := sortedObjects(wk.store)
+			if wk.kind.Fill != nil {
+				wk.kind.Fill(res, objs)
+				continue
+			}
+			list := newUnstructuredList()
+			fillList(list, objs)
+			res.SetObjects(wk.kind.Name, list)
+		}
+		for i, extra := range wn.base.Extras {
+			list := &unstructured.UnstructuredList{}
+			for _, obj := range sortedObjects(wn.extraStores[i]) {
+				list.Items = append(list.Items, *obj.(*unstructured.Unstructured))
+			}
+			res.Extras = append(res.Extras, &ExtraResources{Kind: extra.Kind, Resource: extra.Resource, List: list})
+		}
+		res.ensureLists()
+		res.removeOldRss()
+
+		// related resources that don't match the selectors
+		if w.opts.LabelSelector != "" || w.opts.FieldSelector != "" {
+			res.addRelated(ctx, Clients{Clientset: w.clientset, DynamicClient: w.opts.DynamicClient, MetadataClient: w.opts.MetadataClient})
+		}
+		ress = append(ress, res)
+	}
+
+	return ress
+}
+
+// sortedObjects returns the objects in store sorted by name, to keep the order stable
+func sortedObjects(store cache.Store) []interface{} {
+	objs := store.List()
+	keys := make([]string, len(objs))
+	for i, obj := range objs {
+		keys[i], _ = cache.MetaNamespaceKeyFunc(obj)
+	}
+	sort.Sort(byKeys{objs: objs, keys: keys})
+	return objs
+}
+
+// byKeys sorts objs by keys
+type byKeys struct {
+	objs []interface{}
+	keys []string
+}
+
+func (b byKeys) Len() int           { return len(b.objs) }
+func (b byKeys) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKeys) Swap(i, j int) {
+	b.objs[i], b.objs[j] = b.objs[j], b.objs[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2021 k8sviz authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWatcher(t *testing.T) {
+	cs := fake.NewSimpleClientset(testRes1...)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	ch := make(chan []*Resources)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- NewWatcher(cs, []string{testns}, Options{}).Run(ctx, 10*time.Millisecond, func(ress []*Resources) {
+			ch <- ress
+		})
+	}()
+
+	receive := func() *Resources {
+		select {
+		case ress := <-ch:
+			if len(ress) != 1 {
+				t.Fatalf("Watcher doesn't return resources for one namespace, returned:%v", ress)
+			}
+			return ress[0]
+		case err := <-errCh:
+			t.Fatalf("Watcher failed: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Watcher doesn't call handler")
+		}
+		return nil
+	}
+
+	// Initial resources
+	res := receive()
+	testCases := []struct {
+		kind     string
+		expected []string
+	}{
+		{kind: "pod", expected: []string{"pod1"}},
+		{kind: "svc", expected: []string{"svc1", "svc2"}},
+		{kind: "rs", expected: []string{"rs1", "rs2", "rs3"}},
+		{kind: "hpa", expected: []string{"hpa1"}},
+	}
+	for _, tc := range testCases {
+		names := res.GetResourceNames(tc.kind)
+		if strings.Join(tc.expected, ",") != strings.Join(names, ",") {
+			t.Fatalf("[%s] GetResourceNames doesn't return expected, expected:%v, returned:%v", tc.kind, tc.expected, names)
+		}
+	}
+
+	// Changed resources
+	pod0 := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "pod0",
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		Annotations:   map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "note": "kept"}}}
+	if _, err := cs.CoreV1().Pods(testns).Create(ctx, pod0, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := cs.CoreV1().Services(testns).Delete(ctx, "svc1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		res = receive()
+		if res.HasResource("pod", "pod0") && !res.HasResource("svc", "svc1") {
+			break
+		}
+	}
+	if names := res.GetResourceNames("pod"); strings.Join(names, ",") != "pod0,pod1" {
+		t.Fatalf("GetResourceNames for pod doesn't return expected, expected:[pod0 pod1], returned:%v", names)
+	}
+	if names := res.GetResourceNames("svc"); strings.Join(names, ",") != "svc2" {
+		t.Fatalf("GetResourceNames for svc doesn't return expected, expected:[svc2], returned:%v", names)
+	}
+	// Objects in informers are stripped
+	for _, pod := range res.Pods.Items {
+		if len(pod.ManagedFields) != 0 || pod.Annotations[corev1.LastAppliedConfigAnnotation] != "" {
+			t.Fatalf("Watcher doesn't strip unused fields of pod %s: %v", pod.Name, pod.ObjectMeta)
+		}
+		if pod.Name == "pod0" && pod.Annotations["note"] != "kept" {
+			t.Fatalf("Watcher strips annotations other than the last applied configuration: %v", pod.Annotations)
+		}
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Watcher failed: %v", err)
+	}
+}
+
+func TestWatcherSecretMetadata(t *testing.T) {
+	cs := fake.NewSimpleClientset(testRes1...)
+	// Secrets are only watched through the metadata client
+	cs.PrependReactor("*", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("secrets are got with the clientset")
+	})
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatalf("AddMetaToScheme failed: %v", err)
+	}
+	mc := metadatafake.NewSimpleMetadataClient(scheme, &metav1.PartialObjectMetadata{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: testns, Name: "secret1", Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}"}}})
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	ch := make(chan []*Resources)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- NewWatcher(cs, []string{testns}, Options{MetadataClient: mc}).Run(ctx, 10*time.Millisecond, func(ress []*Resources) {
+			ch <- ress
+		})
+	}()
+
+	var res *Resources
+	select {
+	case ress := <-ch:
+		res = ress[0]
+	case err := <-errCh:
+		t.Fatalf("Watcher failed: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Watcher doesn't call handler")
+	}
+	if names := res.GetResourceNames("secret"); strings.Join(names, ",") != "secret1" {
+		t.Fatalf("GetResourceNames doesn't return expected, expected:[secret1], returned:%v", names)
+	}
+	if _, ok := res.Secrets.Items[0].Annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		t.Fatalf("Watcher doesn't strip last applied configuration of secret1, returned:%v", res.Secrets.Items[0].Annotations)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Watcher failed: %v", err)
+	}
+}
+
+func TestWatcherRegisteredKind(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+	if err := RegisterKind(NewDynamicKind("rollout", []string{"rollouts"}, 2, gvr)); err != nil {
+		t.Fatalf("RegisterKind failed: %v", err)
+	}
+	defer unregisterKind("rollout")
+
+	objs, err := LoadManifests([]string{manifestsDir}, testns)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	dc := NewOfflineDynamicClient(objs)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	ch := make(chan []*Resources)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- NewWatcher(NewOfflineClientset(objs), []string{testns}, Options{DynamicClient: dc}).Run(ctx, 10*time.Millisecond, func(ress []*Resources) {
+			ch <- ress
+		})
+	}()
+
+	receive := func() *Resources {
+		select {
+		case ress := <-ch:
+			return ress[0]
+		case err := <-errCh:
+			t.Fatalf("Watcher failed: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Watcher doesn't call handler")
+		}
+		return nil
+	}
+
+	res := receive()
+	if names := res.GetResourceNames("rollout"); strings.Join(names, ",") != "rollout1" {
+		t.Fatalf("GetResourceNames doesn't return expected, expected:[rollout1], returned:%v", names)
+	}
+
+	// Objects of the registered kind are watched through the dynamic client
+	rollout := &unstructured.Unstructured{}
+	rollout.SetAPIVersion("argoproj.io/v1alpha1")
+	rollout.SetKind("Rollout")
+	rollout.SetNamespace(testns)
+	rollout.SetName("rollout0")
+	if _, err := dc.Resource(gvr).Namespace(testns).Create(ctx, rollout, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	res = receive()
+	if names := res.GetResourceNames("rollout"); strings.Join(names, ",") != "rollout0,rollout1" {
+		t.Fatalf("GetResourceNames doesn't return expected, expected:[rollout0 rollout1], returned:%v", names)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Watcher failed: %v", err)
+	}
+}
+
+func TestWatcherSyncTimeout(t *testing.T) {
+	cs := fake.NewSimpleClientset(testRes1...)
+	// Lists by the informers keep failing after the first collection, which gets the namespace at the end
+	var collected int32
+	cs.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		atomic.StoreInt32(&collected, 1)
+		return false, nil, nil
+	})
+	cs.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if atomic.LoadInt32(&collected) == 0 {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewServiceUnavailable("unreachable")
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	watcher := NewWatcher(cs, []string{testns}, Options{})
+	watcher.SyncTimeout = 100 * time.Millisecond
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watcher.Run(ctx, 10*time.Millisecond, func(ress []*Resources) {})
+	}()
+	select {
+	case err := <-errCh:
+		if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+			t.Fatalf("Watcher expects error for sync timeout, but returned %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Watcher doesn't time out on sync")
+	}
+}